@@ -0,0 +1,161 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"sla_uptime/internal/alert"
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/maintenance"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+	"sla_uptime/internal/target"
+)
+
+// slauptime alerts
+//
+// Menghitung burn rate error budget bulanan (target monthly di sla_targets)
+// setiap target dari ping_results di jendela alerts.short_window dan
+// alerts.long_window, lalu membuka / menutup alert di sla_alerts. Perubahan
+// alert dicatat di log dan dikirim ke alerts.webhook_url jika diisi. Sekali
+// jalan atau terus setiap alerts.interval.
+func runAlerts(args []string) error {
+	cfg, err := loadConfig("alerts", args, nil)
+	if err != nil {
+		return err
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	webhook := alert.NewWebhook(cfg.Alerts.WebhookURL, cfg.Alerts.WebhookTimeout)
+	for {
+		evals, err := evaluateAlerts(cfg, mysqlDB, webhook, time.Now())
+		if err != nil {
+			if cfg.Alerts.Interval == 0 {
+				return err
+			}
+			log.Printf("Evaluasi alert gagal, dicoba lagi dalam %v: %v", cfg.Alerts.Interval, err)
+		}
+
+		if cfg.Alerts.Interval == 0 {
+			return printBurnRates(cfg, evals)
+		}
+		time.Sleep(cfg.Alerts.Interval)
+	}
+}
+
+// Menghitung burn rate semua target yang punya target bulanan, menyimpan
+// perubahan alert lalu melaporkannya ke log dan webhook
+func evaluateAlerts(cfg *config.Config, db *sql.DB, webhook *alert.Webhook, now time.Time) ([]alert.Evaluation, error) {
+	// Aturan dan target dibaca ulang setiap putaran supaya perubahan langsung berlaku
+	set, err := loadRules(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := summary.LoadTargets(db, cfg.Tables.SLATargets, summary.Monthly)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := target.Groups(db, cfg.Tables.IPMonitor)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := calendarOptions(cfg, db, "uptime")
+	if err != nil {
+		return nil, err
+	}
+
+	long, err := burnWindow(cfg, db, set, opts, cfg.Alerts.LongWindow, now)
+	if err != nil {
+		return nil, err
+	}
+	short, err := burnWindow(cfg, db, set, opts, cfg.Alerts.ShortWindow, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var evals []alert.Evaluation
+	for ipID, row := range long {
+		pct, ok := targets.Lookup(ipID, groups[ipID])
+		if !ok || pct >= 100 {
+			continue // tanpa target atau tanpa error budget
+		}
+		e := alert.Evaluation{IPID: ipID, Target: pct, Long: alert.BurnRate(row, pct, cfg.Summary.MissingData)}
+		if row, ok := short[ipID]; ok {
+			e.Short = alert.BurnRate(row, pct, cfg.Summary.MissingData)
+		}
+		evals = append(evals, e)
+	}
+	sort.Slice(evals, func(i, j int) bool { return evals[i].IPID < evals[j].IPID })
+
+	events, err := alert.Apply(db, cfg.Tables.SLAAlerts, evals, cfg.Alerts.BurnRate, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		a := e.Alert
+		if e.Status == alert.Firing {
+			log.Printf("Alert burn rate aktif untuk ip_id %d: %.1fx (%v) dan %.1fx (%v), target %.3f%%, ambang %.1fx",
+				a.IPID, a.ShortBurn, cfg.Alerts.ShortWindow, a.LongBurn, cfg.Alerts.LongWindow, a.Target, a.Threshold)
+		} else {
+			log.Printf("Alert burn rate ip_id %d selesai: %.1fx (%v) dan %.1fx (%v), aktif sejak %s",
+				a.IPID, a.ShortBurn, cfg.Alerts.ShortWindow, a.LongBurn, cfg.Alerts.LongWindow, a.FiredAt.In(cfg.Location()).Format("2006-01-02 15:04:05"))
+		}
+		// Alert sudah tersimpan, webhook yang gagal hanya dicatat
+		if err := webhook.Send(e); err != nil {
+			log.Printf("Webhook alert %d gagal: %v", a.ID, err)
+		}
+	}
+	return evals, nil
+}
+
+// Ringkasan per ip_id untuk jendela [now-window, now) dari sampel yang dihitung ke SLA
+func burnWindow(cfg *config.Config, db *sql.DB, set *rules.Set, opts summary.Options, window time.Duration, now time.Time) (map[int]summary.Row, error) {
+	from := now.Add(-window)
+	filter := rules.Filter{Set: set, Class: rules.SLA}
+	schedule, err := maintenance.Load(db, cfg.Tables, cfg.Location(), from.Add(-cfg.SampleGap()), now)
+	if err != nil {
+		return nil, err
+	}
+	filter.Maintenance = schedule
+
+	rows, err := summary.Collect(db, cfg.Tables.PingResults, filter, from, now, opts)
+	if err != nil {
+		return nil, err
+	}
+	if rows, err = summary.AddSilentTargets(db, cfg.Tables, filter, rows, from, now); err != nil {
+		return nil, err
+	}
+	rows = summary.ApplyCoverage(rows, from, now, cfg.Probe.Interval, cfg.Summary.MissingData, opts.Calendars)
+
+	byIP := make(map[int]summary.Row, len(rows))
+	for _, row := range rows {
+		byIP[row.IPID] = row
+	}
+	return byIP, nil
+}
+
+func printBurnRates(cfg *config.Config, evals []alert.Evaluation) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "ip_id\ttarget %%\tburn %v\tburn %v\talert\t\n", cfg.Alerts.ShortWindow, cfg.Alerts.LongWindow)
+	for _, e := range evals {
+		fmt.Fprintf(w, "%d\t%.3f\t%.2f\t%.2f\t%s\t\n", e.IPID, e.Target, e.Short, e.Long, yesNo(e.Firing(cfg.Alerts.BurnRate)))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"sla_uptime/internal/calendar"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/target"
+)
+
+// slauptime calendars [-from -to]: tampilkan kalender jam operasional, jumlah
+// target yang memakainya dan lama jam operasional dalam rentang waktu, untuk
+// mengecek jam dan hari libur sebelum berlaku
+func runCalendars(args []string) error {
+	var fromFlag, toFlag string
+	cfg, err := loadConfig("calendars", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&fromFlag, "from", "", "awal rentang (default awal hari ini), contoh 2024-01-01")
+		fs.StringVar(&toFlag, "to", "", "akhir rentang (default 7 hari setelah -from)")
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().In(cfg.Location())
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if fromFlag != "" {
+		if from, err = parseTime(fromFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	to := from.AddDate(0, 0, 7)
+	if toFlag != "" {
+		if to, err = parseTime(toFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("-from harus sebelum -to")
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	set, err := calendar.Load(mysqlDB, cfg.Tables)
+	if err != nil {
+		return err
+	}
+	names, err := target.Calendars(mysqlDB, cfg.Tables.IPMonitor)
+	if err != nil {
+		return err
+	}
+	targets := make(map[string]int)
+	for _, name := range names {
+		targets[name]++
+	}
+
+	calendars := set.Calendars()
+	sorted := make([]string, 0, len(calendars))
+	for name := range calendars {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "nama\tzona waktu\tjam operasional\thari libur\ttarget\tjam dalam rentang")
+	for _, name := range sorted {
+		c := calendars[name]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%.1f\n", c.Name, c.Location, c.Hours, c.Holidays(),
+			targets[name], c.Covered(from, to).Hours())
+	}
+	return w.Flush()
+}
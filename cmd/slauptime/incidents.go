@@ -0,0 +1,123 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/incident"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+)
+
+// slauptime incidents [build|list]
+//
+// build menyusun insiden dari ping_results yang belum diproses, sekali jalan
+// atau terus setiap incidents.interval supaya insiden terbuka ikut diperbarui.
+// list menampilkan insiden yang beririsan dengan rentang -from/-to.
+func runIncidents(args []string) error {
+	kind, args := splitKind(args, "build")
+	if kind != "build" && kind != "list" {
+		return fmt.Errorf("pemakaian: slauptime incidents [build|list] [flag]")
+	}
+
+	var fromFlag, toFlag string
+	var ipID int
+	var openOnly bool
+	cfg, err := loadConfig("incidents "+kind, args, func(fs *flag.FlagSet) {
+		if kind == "list" {
+			fs.StringVar(&fromFlag, "from", "", "awal rentang (default 24 jam terakhir), contoh 2024-01-31 13:00")
+			fs.StringVar(&toFlag, "to", "", "akhir rentang (default sekarang)")
+			fs.IntVar(&ipID, "ip", 0, "hanya tampilkan ip_id ini")
+			fs.BoolVar(&openOnly, "open", false, "hanya insiden yang masih berlangsung")
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	if kind == "build" {
+		return buildIncidents(cfg, mysqlDB)
+	}
+
+	to := time.Now()
+	if toFlag != "" {
+		if to, err = parseTime(toFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	from := to.Add(-24 * time.Hour)
+	if fromFlag != "" {
+		if from, err = parseTime(fromFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("-from harus sebelum -to")
+	}
+
+	incidents, err := incident.List(mysqlDB, cfg.Tables.Incidents, from, to, ipID, openOnly)
+	if err != nil {
+		return err
+	}
+
+	loc := cfg.Location()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "id\tip_id\tmulai\tselesai\tlama\tklasifikasi\tstatus_id\treason_id\tgagal")
+	for _, inc := range incidents {
+		end := "berlangsung"
+		if !inc.Open() {
+			end = inc.EndedAt.Time.In(loc).Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n", inc.ID, inc.IPID,
+			inc.StartedAt.In(loc).Format("2006-01-02 15:04:05"), end, inc.Duration(), inc.Class,
+			nullID(inc.StatusID), nullID(inc.ReasonID), inc.FailCount)
+	}
+	return w.Flush()
+}
+
+// Proses ping_results baru sampai habis, lalu berhenti atau ulangi tiap incidents.interval
+func buildIncidents(cfg *config.Config, db *sql.DB) error {
+	for {
+		// Aturan dibaca ulang setiap putaran supaya perubahan sla_rules langsung berlaku
+		result, err := buildIncidentsOnce(cfg, db)
+		if err != nil {
+			if cfg.Incidents.Interval == 0 {
+				return err
+			}
+			// Mode jalan terus: coba lagi di putaran berikutnya, posisi tetap aman
+			log.Printf("Build insiden gagal, dicoba lagi dalam %v: %v", cfg.Incidents.Interval, err)
+		} else {
+			log.Printf("Insiden: %d sampel diproses, %d dilewati, %d insiden baru, %d selesai, posisi id %d (pasti sampai %d)",
+				result.Processed, result.Skipped, result.Opened, result.Closed, result.LastID, result.SettledID)
+		}
+
+		if cfg.Incidents.Interval == 0 {
+			return nil
+		}
+		time.Sleep(cfg.Incidents.Interval)
+	}
+}
+
+func buildIncidentsOnce(cfg *config.Config, db *sql.DB) (incident.Result, error) {
+	set, err := loadRules(cfg, db)
+	if err != nil {
+		return incident.Result{}, err
+	}
+	return incident.Build(db, cfg.Tables, set, cfg.Location(), cfg.Incidents.BatchSize, cfg.Incidents.SettleTime)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"sla_uptime/internal/kpi"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+)
+
+// slauptime kpi [daily|weekly|monthly] [-from -to]
+//
+// Menghitung MTTR, MTBF, jumlah insiden dan availability per ip_id dan per
+// grup dari tabel incidents (jalankan "incidents build" dulu), menyimpannya di
+// sla_kpi lalu menampilkannya. Tanpa -from hanya periode yang sedang berjalan.
+func runKPI(args []string) error {
+	kind, args := splitKind(args, string(summary.Monthly))
+	period := summary.Period(kind)
+	if period != summary.Daily && period != summary.Weekly && period != summary.Monthly {
+		return fmt.Errorf("pemakaian: slauptime kpi [daily|weekly|monthly] [flag]")
+	}
+
+	var fromFlag, toFlag string
+	var save bool
+	cfg, err := loadConfig("kpi "+kind, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&fromFlag, "from", "", "awal rentang (default awal periode sekarang), contoh 2024-01-01")
+		fs.StringVar(&toFlag, "to", "", "akhir rentang, tidak termasuk (default sekarang)")
+		fs.BoolVar(&save, "save", true, "simpan hasil ke tabel sla_kpi")
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	from := period.Start(now)
+	to := now
+	if fromFlag != "" {
+		if from, err = parseTime(fromFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if toFlag != "" {
+		if to, err = parseTime(toFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("-from harus sebelum -to")
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	loc := cfg.Location()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "periode\tip_id / grup\ttarget\tinsiden\tterbuka\tdown menit\tMTTR menit\tMTBF jam\tavailability %\t")
+	for start := period.Start(from); start.Before(to); start = period.Next(start) {
+		kpis, err := kpi.Compute(mysqlDB, cfg.Tables, period, start, now)
+		if err != nil {
+			return err
+		}
+		if save {
+			if err := kpi.Store(mysqlDB, cfg.Tables.SLAKPI, period, start, kpis); err != nil {
+				return err
+			}
+		}
+
+		for _, k := range kpis {
+			scope := fmt.Sprint(k.IPID)
+			if k.Group != "" {
+				scope = "grup " + k.Group
+			}
+			mttr, mtbf := "-", "-"
+			if k.MTTR.Valid {
+				mttr = fmt.Sprintf("%.1f", k.MTTR.Float64/60)
+			}
+			if k.MTBF.Valid {
+				mtbf = fmt.Sprintf("%.1f", k.MTBF.Float64/3600)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%.1f\t%s\t%s\t%.3f\t\n", start.In(loc).Format("2006-01-02 15:04"), scope,
+				k.Targets, k.Incidents, k.Open, k.Downtime.Minutes(), mttr, mtbf, k.Availability)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,125 @@
+// Command slauptime adalah satu binary untuk semua pekerjaan SLA uptime:
+// probe target, ringkasan per jam, upload dari SQLite, migrasi tabel dan laporan.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"sla_uptime/internal/config"
+)
+
+const usage = `Pemakaian: slauptime <subcommand> [flag]
+
+Subcommand:
+  probe                 probe semua target di ip_monitor setiap probe.interval
+  summarize uptime      ringkasan jam lalu ke summary_uptime
+  summarize downtime    ringkasan jam lalu ke summary_downtime
+  rollup [daily|weekly|monthly]
+                        hitung ulang rollup summary_uptime per hari/minggu/bulan
+  upload [uptime|downtime]
+                        ringkasan dari SQLite lokal ke uptime_summary
+  upload raw            kirim ping_results mentah dari SQLite lokal ke MySQL (watermark)
+  migrate [up|down|status]
+                        kelola skema database (-db mysql|sqlite)
+  report                tampilkan uptime per target dari summary_uptime
+  rules                 tampilkan aturan klasifikasi status_id / reason_id yang berlaku
+  maintenance           tampilkan jadwal jendela maintenance (-from -to)
+  calendars             tampilkan kalender jam operasional (-from -to)
+  incidents [build|list]
+                        susun insiden down dari ping_results / tampilkan insiden
+  kpi [daily|weekly|monthly]
+                        MTTR, MTBF dan availability per target dan grup dari insiden
+  sla [daily|weekly|monthly]
+                        pencapaian target SLA, sisa error budget dan breach per periode
+  alerts                alert burn rate error budget bulanan (jendela pendek dan panjang)
+
+Semua subcommand menerima -config dan override konfigurasi, lihat "slauptime <subcommand> -h".
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	var err error
+	switch cmd {
+	case "probe":
+		err = runProbe(args)
+	case "summarize":
+		err = runSummarize(args)
+	case "rollup":
+		err = runRollup(args)
+	case "upload":
+		err = runUpload(args)
+	case "migrate":
+		err = runMigrate(args)
+	case "report":
+		err = runReport(args)
+	case "rules":
+		err = runRules(args)
+	case "maintenance":
+		err = runMaintenance(args)
+	case "calendars":
+		err = runCalendars(args)
+	case "incidents":
+		err = runIncidents(args)
+	case "kpi":
+		err = runKPI(args)
+	case "sla":
+		err = runSLA(args)
+	case "alerts":
+		err = runAlerts(args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "subcommand tidak dikenal: %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Membuat FlagSet untuk subcommand, flag khusus subcommand didaftarkan lewat extra
+// sebelum konfigurasi dibaca
+func loadConfig(name string, args []string, extra func(fs *flag.FlagSet)) (*config.Config, error) {
+	fs := flag.NewFlagSet("slauptime "+name, flag.ExitOnError)
+	if extra != nil {
+		extra(fs)
+	}
+	return config.Load(fs, args)
+}
+
+// Memisahkan argumen posisi pertama (misalnya "uptime") dari flag
+func splitKind(args []string, def string) (string, []string) {
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		return args[0], args[1:]
+	}
+	return def, args
+}
+
+// Format waktu yang diterima flag -from / -to
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// Membaca -from / -to di zona waktu report.timezone
+func parseTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("format waktu tidak dikenal: %q (contoh: 2024-01-31 13:00)", value)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"sla_uptime/internal/maintenance"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+)
+
+// slauptime maintenance [-from -to]: tampilkan kejadian jendela maintenance
+// dalam rentang waktu, untuk mengecek jadwal cron sebelum berlaku
+func runMaintenance(args []string) error {
+	var fromFlag, toFlag string
+	cfg, err := loadConfig("maintenance", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&fromFlag, "from", "", "awal rentang (default sekarang), contoh 2024-01-31 13:00")
+		fs.StringVar(&toFlag, "to", "", "akhir rentang (default 7 hari setelah -from)")
+	})
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+	if fromFlag != "" {
+		if from, err = parseTime(fromFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	to := from.Add(7 * 24 * time.Hour)
+	if toFlag != "" {
+		if to, err = parseTime(toFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("-from harus sebelum -to")
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	schedule, err := maintenance.Load(mysqlDB, cfg.Tables, cfg.Location(), from, to)
+	if err != nil {
+		return err
+	}
+
+	loc := cfg.Location()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "id\ttarget\tmulai\tselesai\tcron\tcatatan")
+	for _, iv := range schedule.Intervals() {
+		cron := "-"
+		if iv.Window.Cron != nil {
+			cron = iv.Window.Cron.String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", iv.Window.ID, iv.Window.Target(),
+			iv.Start.In(loc).Format("2006-01-02 15:04"), iv.End.In(loc).Format("2006-01-02 15:04"), cron, iv.Window.Note)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+)
+
+// slauptime migrate [up|down|status]: kelola skema MySQL (default) atau SQLite lokal (-db sqlite)
+func runMigrate(args []string) error {
+	action, args := splitKind(args, "up")
+	if action != "up" && action != "down" && action != "status" {
+		return fmt.Errorf("pemakaian: slauptime migrate [up|down|status] [-db mysql|sqlite] [-steps N]")
+	}
+
+	var dbName string
+	var steps int
+	cfg, err := loadConfig("migrate "+action, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&dbName, "db", "mysql", "database yang dimigrasi: mysql atau sqlite (sqlite.path)")
+		fs.IntVar(&steps, "steps", 1, "jumlah migrasi yang dibatalkan untuk migrate down")
+	})
+	if err != nil {
+		return err
+	}
+	if steps < 1 {
+		return fmt.Errorf("-steps minimal 1")
+	}
+
+	db, dialect, err := openForMigrate(cfg, dbName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		done, err := migrate.Up(db, dialect, cfg)
+		for _, m := range done {
+			fmt.Printf("Migrasi %04d_%s diterapkan\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Printf("Skema %s sudah terbaru\n", dialect)
+		}
+	case "down":
+		done, err := migrate.Down(db, dialect, cfg, steps)
+		for _, m := range done {
+			fmt.Printf("Migrasi %04d_%s dibatalkan\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrate.List(db, dialect)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "versi\tnama\tstatus")
+		for _, s := range statuses {
+			state := "belum dijalankan"
+			if !s.AppliedAt.IsZero() {
+				state = "diterapkan " + s.AppliedAt.In(cfg.Location()).Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, state)
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+func openForMigrate(cfg *config.Config, dbName string) (*sql.DB, migrate.Dialect, error) {
+	switch dbName {
+	case "mysql":
+		db, err := store.OpenMySQL(cfg.MySQL)
+		return db, migrate.MySQL, err
+	case "sqlite":
+		db, err := store.OpenSQLite(cfg.SQLite.Path)
+		return db, migrate.SQLite, err
+	default:
+		return nil, "", fmt.Errorf("-db harus mysql atau sqlite, didapat %q", dbName)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/probe"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/target"
+	"sla_uptime/internal/writer"
+)
+
+// Target yang sudah punya prober sesuai check_type
+type probeTarget struct {
+	target.Target
+	Prober probe.Prober
+}
+
+func runProbe(args []string) error {
+	cfg, err := loadConfig("probe", args, nil)
+	if err != nil {
+		return err
+	}
+
+	opts := probe.Options{
+		Timeout:  cfg.Probe.Timeout,
+		Count:    cfg.Probe.Count,
+		Interval: cfg.Probe.PacketInterval,
+	}
+	icmp, err := probe.NewICMP(cfg.Probe.Mode, opts)
+	if err != nil {
+		return fmt.Errorf("konfigurasi ping tidak valid: %w", err)
+	}
+
+	// Koneksi MySQL untuk data monitoring
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	// Hasil probe ditulis ke MySQL, atau ke SQLite lokal untuk dikirim nanti lewat upload
+	resultDB, resultDialect := mysqlDB, migrate.MySQL
+	if cfg.Probe.Sink == "sqlite" {
+		sqliteDB, err := store.OpenSQLite(cfg.SQLite.Path)
+		if err != nil {
+			return err
+		}
+		defer sqliteDB.Close()
+
+		// SQLite lokal milik prober sendiri, jadi migrasinya langsung dijalankan
+		if _, err := migrate.Up(sqliteDB, migrate.SQLite, cfg); err != nil {
+			return err
+		}
+		resultDB, resultDialect = sqliteDB, migrate.SQLite
+	}
+
+	// Hasil probe dikumpulkan lalu ditulis batch oleh writer, jadi siklus
+	// probe tidak menunggu INSERT selesai
+	var w *writer.Writer
+	if cfg.Probe.Sink == "mysql" && cfg.Spool.Path != "" {
+		// Saat MySQL tidak bisa dihubungi hasil probe disimpan di spool lokal
+		// lalu dikirim ulang sesuai urutan setelah MySQL kembali
+		spool, err := writer.OpenSpool(cfg.Spool.Path)
+		if err != nil {
+			return err
+		}
+		defer spool.Close()
+
+		w, err = writer.NewWithSpool(resultDB, resultDialect, cfg.Tables.PingResults, cfg.Writer, spool, cfg.Spool.RetryInterval)
+		if err != nil {
+			return err
+		}
+	} else {
+		w = writer.New(resultDB, resultDialect, cfg.Tables.PingResults, cfg.Writer)
+	}
+	defer func() {
+		w.Close()
+		log.Printf("Writer %s berhenti: %s", cfg.Tables.PingResults, w.Stats())
+	}()
+
+	// Berhenti dengan rapi saat Ctrl+C / SIGTERM supaya isi antrian writer tidak hilang
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Fungsi untuk mengambil data IP
+	getTargets := func() []probeTarget {
+		targets, err := target.Load(mysqlDB, cfg.Tables.IPMonitor)
+		if err != nil {
+			log.Print(err)
+			return nil
+		}
+
+		probeTargets := make([]probeTarget, 0, len(targets))
+		for _, t := range targets {
+			p, err := probe.New(t.CheckType, t.CheckParams, icmp, opts)
+			if err != nil {
+				log.Printf("Cek untuk ip_id %d (%s) dilewati: %v", t.ID, t.IP, err)
+				continue
+			}
+			probeTargets = append(probeTargets, probeTarget{Target: t, Prober: p})
+		}
+		return probeTargets
+	}
+
+	// Timer untuk update data ip_monitor
+	updateTicker := time.NewTicker(cfg.Probe.RefreshInterval)
+	defer updateTicker.Stop()
+
+	// Channel untuk menyimpan data IP terbaru
+	targetChan := make(chan []probeTarget)
+
+	// Goroutine untuk update data, berhenti bersama ctx supaya tidak tertahan
+	// mengirim ke targetChan setelah loop utama selesai
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-updateTicker.C:
+			}
+			targets := getTargets()
+			if len(targets) == 0 {
+				continue
+			}
+			select {
+			case targetChan <- targets:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Inisialisasi data IP pertama kali
+	currentTargets := getTargets()
+	if len(currentTargets) == 0 {
+		return fmt.Errorf("tidak ada IP yang ditemukan di %s", cfg.Tables.IPMonitor)
+	}
+
+	// Loop utama
+	pingTicker := time.NewTicker(cfg.Probe.Interval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Print("Probe dihentikan, menyimpan sisa hasil ping...")
+			return nil
+		case newTargets := <-targetChan:
+			currentTargets = newTargets
+		case <-pingTicker.C:
+			start := time.Now()
+			probeWithConcurrency(cfg, currentTargets, w)
+			elapsed := time.Since(start)
+			if elapsed > cfg.Probe.Interval {
+				log.Printf("Peringatan: Siklus ping memakan waktu lebih dari %v: %v", cfg.Probe.Interval, elapsed)
+			}
+		}
+	}
+}
+
+// Probe semua target dengan batasan konkurensi lalu kirim hasilnya ke writer
+func probeWithConcurrency(cfg *config.Config, targets []probeTarget, w *writer.Writer) {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, cfg.Probe.Concurrency)
+
+	for _, t := range targets {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(t probeTarget) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			start := time.Now().UTC()
+			result := probe.Confirm(t.Prober, t.IP, cfg.Probe.MaxLoss, cfg.Probe.DownRetries, cfg.Probe.RetryInterval)
+			w.Write(writer.Sample{
+				IPID:      t.ID,
+				Timestamp: start,
+				StatusID:  t.StatusID,
+				ReasonID:  t.ReasonID,
+				Result:    result,
+			})
+		}(t)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+)
+
+// slauptime report: uptime per target dari summary_uptime untuk rentang waktu tertentu
+func runReport(args []string) error {
+	var fromFlag, toFlag string
+	var ipID int
+	cfg, err := loadConfig("report", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&fromFlag, "from", "", "awal rentang (default 24 jam terakhir), contoh 2024-01-31 13:00")
+		fs.StringVar(&toFlag, "to", "", "akhir rentang (default sekarang)")
+		fs.IntVar(&ipID, "ip", 0, "hanya tampilkan ip_id ini")
+	})
+	if err != nil {
+		return err
+	}
+
+	to := time.Now()
+	if toFlag != "" {
+		if to, err = parseTime(toFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	from := to.Add(-24 * time.Hour)
+	if fromFlag != "" {
+		if from, err = parseTime(fromFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("-from harus sebelum -to")
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	// Baris lama (sebelum ada up_seconds dst) dihitung dari jumlah sampel x probe.interval
+	sampleSeconds := cfg.Probe.Interval.Seconds()
+	query := `
+		SELECT s.ip_id, COALESCE(m.ip, ''), COUNT(*),
+		       SUM(s.success_count), SUM(s.fail_count),
+		       SUM(CASE WHEN s.up_seconds + s.down_seconds + s.unknown_seconds > 0 THEN s.up_seconds ELSE s.success_count * ? END),
+		       SUM(CASE WHEN s.up_seconds + s.down_seconds + s.unknown_seconds > 0 THEN s.down_seconds ELSE s.fail_count * ? END),
+		       SUM(CASE WHEN s.up_seconds + s.down_seconds + s.unknown_seconds > 0 THEN s.unknown_seconds ELSE s.unknown_count * ? END),
+		       AVG(s.response_time)
+		FROM ` + cfg.Tables.SummaryUptime + ` s
+		LEFT JOIN ` + cfg.Tables.IPMonitor + ` m ON m.id = s.ip_id
+		WHERE s.timestamp >= ? AND s.timestamp < ?`
+	queryArgs := []interface{}{sampleSeconds, sampleSeconds, sampleSeconds, from, to}
+	if ipID != 0 {
+		query += ` AND s.ip_id = ?`
+		queryArgs = append(queryArgs, ipID)
+	}
+	query += ` GROUP BY s.ip_id, m.ip ORDER BY s.ip_id`
+
+	rows, err := mysqlDB.Query(query, queryArgs...)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil data %s: %w", cfg.Tables.SummaryUptime, err)
+	}
+	defer rows.Close()
+
+	loc := cfg.Location()
+	fmt.Printf("Uptime %s - %s (%s)\n\n", from.In(loc).Format("2006-01-02 15:04"), to.In(loc).Format("2006-01-02 15:04"), loc)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ip_id\tip\tjam\tsukses\tgagal\tdown menit\tunknown menit\tcoverage %\tuptime %\tresponse ms\t")
+	for rows.Next() {
+		var id, hours, success, fail int
+		var ip string
+		var up, down, unknown float64
+		var responseTime sql.NullFloat64 // NULL jika tidak ada sampel sukses
+		if err := rows.Scan(&id, &ip, &hours, &success, &fail, &up, &down, &unknown, &responseTime); err != nil {
+			return fmt.Errorf("gagal membaca baris: %w", err)
+		}
+
+		// Persentase dihitung dari total waktu up/down, bukan rata-rata persentase per jam
+		uptime := summary.Uptime(up, down, unknown, cfg.Summary.MissingData)
+		coverage := 100.0
+		if total := up + down + unknown; total > 0 {
+			coverage = (up + down) / total * 100
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%.1f\t%.1f\t%.1f\t%s\t%s\t\n",
+			id, ip, hours, success, fail, down/60, unknown/60, coverage, nullable(uptime, 3), nullable(responseTime, 2))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+)
+
+// slauptime rollup [daily|weekly|monthly] [-from -to]
+//
+// Menghitung ulang rollup summary_uptime untuk setiap periode yang beririsan
+// dengan [-from, -to). Tanpa periode semua rollup dihitung, tanpa -from hanya
+// periode yang berisi jam lalu. summarize uptime sudah me-rollup ulang periode
+// dari jam yang diringkasnya, jadi subcommand ini untuk pengisian awal atau
+// setelah summary_uptime diubah manual.
+func runRollup(args []string) error {
+	kind, args := splitKind(args, "")
+	periods := summary.Periods
+	if kind != "" {
+		if kind != string(summary.Daily) && kind != string(summary.Weekly) && kind != string(summary.Monthly) {
+			return fmt.Errorf("pemakaian: slauptime rollup [daily|weekly|monthly] [flag]")
+		}
+		periods = []summary.Period{summary.Period(kind)}
+	}
+
+	var fromFlag, toFlag string
+	cfg, err := loadConfig("rollup", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&fromFlag, "from", "", "awal rentang (default jam lalu), contoh 2024-01-01")
+		fs.StringVar(&toFlag, "to", "", "akhir rentang, tidak termasuk (default awal jam sekarang)")
+	})
+	if err != nil {
+		return err
+	}
+
+	// Seperti summarize, -from dan -to dibulatkan ke awal jam UTC
+	currentHour := time.Now().UTC().Truncate(time.Hour)
+	from := currentHour.Add(-1 * time.Hour)
+	to := currentHour
+	if fromFlag != "" {
+		if from, err = parseTime(fromFlag, cfg.Location()); err != nil {
+			return err
+		}
+		from = from.UTC().Truncate(time.Hour)
+	}
+	if toFlag != "" {
+		if to, err = parseTime(toFlag, cfg.Location()); err != nil {
+			return err
+		}
+		to = to.UTC().Truncate(time.Hour)
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("-from harus sebelum -to")
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, period := range periods {
+		for start := period.Start(from); start.Before(to); start = period.Next(start) {
+			if err := rollupPeriod(cfg, mysqlDB, period, start); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Me-rollup ulang setiap hari, minggu dan bulan yang berisi salah satu jam di
+// hours, dipanggil setelah jam-jam tersebut diringkas (ulang)
+func rollupHours(cfg *config.Config, db *sql.DB, hours []time.Time) error {
+	var errs []error
+	for _, period := range summary.Periods {
+		starts := make(map[time.Time]bool)
+		for _, hour := range hours {
+			starts[period.Start(hour)] = true
+		}
+		sorted := make([]time.Time, 0, len(starts))
+		for start := range starts {
+			sorted = append(sorted, start)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+		for _, start := range sorted {
+			if err := rollupPeriod(cfg, db, period, start); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func rollupPeriod(cfg *config.Config, db *sql.DB, period summary.Period, start time.Time) error {
+	n, err := summary.Rollup(db, cfg.Tables, period, start, cfg.Summary.MissingData, cfg.Probe.Interval.Seconds())
+	if err != nil {
+		log.Printf("Rollup %s %s gagal: %v", period, start.Format("2006-01-02"), err)
+		return err
+	}
+	fmt.Printf("Rollup %s %s: %d target\n", period, start.Format("2006-01-02"), n)
+	_, err = storeAttainment(cfg, db, period, start, time.Now())
+	return err
+}
+
+// Menghitung dan menyimpan pencapaian target SLA periode itu dari rollup-nya.
+// Target yang baru breach (belum breach di sla_attainment sebelumnya) dicatat
+// di log.
+func storeAttainment(cfg *config.Config, db *sql.DB, period summary.Period, start, now time.Time) ([]summary.Attainment, error) {
+	rows, err := summary.ComputeAttainment(db, cfg.Tables, period, start, now, cfg.Summary.MissingData)
+	var before map[summary.Scope]bool
+	if err == nil {
+		before, err = summary.Breached(db, cfg.Tables.SLAAttainment, period, start)
+	}
+	if err == nil {
+		err = summary.StoreAttainment(db, cfg.Tables.SLAAttainment, period, start, rows)
+	}
+	if err != nil {
+		log.Printf("Pencapaian SLA %s %s gagal: %v", period, start.Format("2006-01-02"), err)
+		return nil, err
+	}
+	for _, a := range rows {
+		if a.Breached && !before[a.Scope()] {
+			log.Printf("SLA %s %s breach untuk %s: target %.3f%%, sisa budget %.1f menit",
+				period, start.Format("2006-01-02"), attainmentScope(a), a.Target, a.Remaining.Minutes())
+		}
+	}
+	return rows, nil
+}
+
+func attainmentScope(a summary.Attainment) string {
+	if a.Group != "" {
+		return "grup " + a.Group
+	}
+	return fmt.Sprintf("ip_id %d", a.IPID)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/store"
+)
+
+// slauptime rules: tampilkan aturan klasifikasi yang akan dipakai summarize,
+// dari tabel sla_rules atau dari filters di konfigurasi jika tabel kosong
+func runRules(args []string) error {
+	cfg, err := loadConfig("rules", args, nil)
+	if err != nil {
+		return err
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	set, err := rules.Load(mysqlDB, cfg.Tables.SLARules, cfg.Filters)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Aturan dari %s (yang paling spesifik menang, selain itu excluded):\n", set.Source)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "status_id\treason_id\tklasifikasi\tcatatan")
+	for _, r := range set.Rules() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", nullID(r.StatusID), nullID(r.ReasonID), r.Class, r.Note)
+	}
+	for _, status := range set.NullReasonExcluded() {
+		fmt.Fprintf(w, "%d\tNULL\t%s\treason_id NULL, seperti query lama\n", status, rules.Excluded)
+	}
+	return w.Flush()
+}
+
+func nullID(v sql.NullInt64) string {
+	if !v.Valid {
+		return "*"
+	}
+	return fmt.Sprint(v.Int64)
+}
+
+// Membaca aturan klasifikasi setiap kali subcommand jalan, supaya perubahan di
+// tabel sla_rules langsung berlaku tanpa deploy
+func loadRules(cfg *config.Config, db *sql.DB) (*rules.Set, error) {
+	set, err := rules.Load(db, cfg.Tables.SLARules, cfg.Filters)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Memakai %d aturan klasifikasi dari %s", len(set.Rules()), set.Source)
+	return set, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+)
+
+// slauptime sla [daily|weekly|monthly] [-from -to]
+//
+// Menghitung pencapaian target SLA (sla_targets) dari rollup setiap periode
+// di [-from, -to), menyimpannya di sla_attainment lalu menampilkannya. Tanpa
+// -from hanya periode yang sedang berjalan. rollup dan summarize uptime sudah
+// menghitung ulang pencapaian periode yang di-rollup-nya.
+func runSLA(args []string) error {
+	kind, args := splitKind(args, string(summary.Monthly))
+	period := summary.Period(kind)
+	if period != summary.Daily && period != summary.Weekly && period != summary.Monthly {
+		return fmt.Errorf("pemakaian: slauptime sla [daily|weekly|monthly] [flag]")
+	}
+
+	var fromFlag, toFlag string
+	cfg, err := loadConfig("sla "+kind, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&fromFlag, "from", "", "awal rentang (default awal periode sekarang), contoh 2024-01-01")
+		fs.StringVar(&toFlag, "to", "", "akhir rentang, tidak termasuk (default sekarang)")
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	from := period.Start(now)
+	to := now
+	if fromFlag != "" {
+		if from, err = parseTime(fromFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if toFlag != "" {
+		if to, err = parseTime(toFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("-from harus sebelum -to")
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	loc := cfg.Location()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "periode\tip_id / grup\ttarget %\tpencapaian %\tbudget menit\tterpakai menit\tsisa menit\tbreach\tselesai\t")
+	for start := period.Start(from); start.Before(to); start = period.Next(start) {
+		rows, err := storeAttainment(cfg, mysqlDB, period, start, now)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range rows {
+			scope := fmt.Sprint(a.IPID)
+			if a.Group != "" {
+				scope = "grup " + a.Group
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.3f\t%s\t%.1f\t%.1f\t%.1f\t%s\t%s\t\n", start.In(loc).Format("2006-01-02 15:04"), scope,
+				a.Target, nullable(a.Attainment, 3), a.Budget.Minutes(), a.Consumed.Minutes(), a.Remaining.Minutes(),
+				yesNo(a.Breached), yesNo(a.Complete))
+		}
+	}
+	return w.Flush()
+}
+
+// Angka dengan prec desimal, "-" jika NULL (tidak ada data)
+func nullable(v sql.NullFloat64, prec int) string {
+	if !v.Valid {
+		return "-"
+	}
+	return strconv.FormatFloat(v.Float64, 'f', prec, 64)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "ya"
+	}
+	return "tidak"
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"sla_uptime/internal/calendar"
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/maintenance"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+)
+
+// slauptime summarize uptime|downtime [-from -to] [-force]
+//
+// Tanpa -from hanya jam lalu yang diringkas, lalu jam terlewat dalam
+// summary.catchup_lookback diisi otomatis. Dengan -from/-to setiap jam di
+// rentang itu diringkas satu per satu; jam yang sudah selesai dilewati
+// kecuali -force (misalnya setelah filter diubah). Setiap jam dicatat di
+// ledger summary_runs. Untuk uptime, rollup harian/mingguan/bulanan dari jam
+// yang diringkas ikut dihitung ulang.
+func runSummarize(args []string) error {
+	kind, args := splitKind(args, "")
+	if kind != "uptime" && kind != "downtime" {
+		return fmt.Errorf("pemakaian: slauptime summarize uptime|downtime [flag]")
+	}
+
+	var fromFlag, toFlag string
+	var force bool
+	cfg, err := loadConfig("summarize "+kind, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&fromFlag, "from", "", "awal rentang backfill (default jam lalu), contoh 2024-01-31 13:00")
+		fs.StringVar(&toFlag, "to", "", "akhir rentang backfill, tidak termasuk (default awal jam sekarang)")
+		fs.BoolVar(&force, "force", false, "hitung ulang jam yang sudah punya ringkasan")
+	})
+	if err != nil {
+		return err
+	}
+
+	// Jam diringkas dalam UTC, -from dan -to dibulatkan ke awal jam UTC yang
+	// sama supaya [from, to) selalu berisi jam utuh
+	var fromArg, toArg time.Time
+	if fromFlag != "" {
+		if fromArg, err = parseTime(fromFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	if toFlag != "" {
+		if toArg, err = parseTime(toFlag, cfg.Location()); err != nil {
+			return err
+		}
+	}
+	from, to, err := summary.HourRange(fromArg, toArg, time.Now())
+	if err != nil {
+		return err
+	}
+	if !toArg.IsZero() && toArg.UTC().Truncate(time.Hour).After(to) {
+		log.Printf("-to dibatasi sampai %s karena jam sekarang belum selesai", to.Format(time.RFC3339))
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	set, err := loadRules(cfg, mysqlDB)
+	if err != nil {
+		return err
+	}
+
+	runType := summary.RunScheduled
+	if fromFlag != "" || toFlag != "" {
+		runType = summary.RunBackfill
+	}
+
+	var errs []error
+	write, table := hourWriter(cfg, mysqlDB, set, kind)
+	result, err := summary.Backfill(mysqlDB, cfg.Tables.SummaryRuns, table, kind, from, to, runType, force, write)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	changed := result.Done
+	fmt.Printf("Selesai: %d jam diringkas, %d jam dilewati (sudah ada), %d jam gagal\n", len(result.Done), result.Skipped, result.Failed)
+
+	// Run terjadwal sekalian mengisi jam yang terlewat (cron mati, error, dst)
+	if runType == summary.RunScheduled && cfg.Summary.CatchupLookback > 0 {
+		filled, err := catchUp(cfg, mysqlDB, set, kind, from)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		changed = append(changed, filled...)
+	}
+
+	if kind == "uptime" && len(changed) > 0 {
+		if err := rollupHours(cfg, mysqlDB, changed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Mencari jam dalam summary.catchup_lookback sebelum "before" yang punya data
+// mentah tapi belum diringkas, lalu meringkasnya. Mengembalikan jam yang
+// berhasil diisi.
+func catchUp(cfg *config.Config, db *sql.DB, set *rules.Set, kind string, before time.Time) ([]time.Time, error) {
+	from := before.Add(-cfg.Summary.CatchupLookback)
+	filter, table := summaryTarget(cfg, set, kind)
+	write, _ := hourWriter(cfg, db, set, kind)
+	schedule, err := maintenance.Load(db, cfg.Tables, cfg.Location(), from, before)
+	if err != nil {
+		return nil, err
+	}
+	filter.Maintenance = schedule
+	gaps, err := summary.Gaps(db, cfg.Tables, kind, filter, table, from, before)
+	if err != nil {
+		return nil, err
+	}
+	if len(gaps) == 0 {
+		return nil, nil
+	}
+
+	log.Printf("Ditemukan %d jam %s yang terlewat, mulai catch-up", len(gaps), kind)
+	var errs []error
+	var filled []time.Time
+	var labels []string
+	for _, hour := range gaps {
+		if _, err := summary.SummarizeHour(db, cfg.Tables.SummaryRuns, table, kind, hour, summary.RunCatchup, true, write); err != nil {
+			log.Printf("Catch-up jam %s gagal: %v", hour.Format(time.RFC3339), err)
+			errs = append(errs, err)
+			continue
+		}
+		filled = append(filled, hour)
+		labels = append(labels, hour.Format("2006-01-02 15:04Z07:00"))
+	}
+	fmt.Printf("Catch-up: %d jam diisi (%s), %d jam gagal\n", len(filled), strings.Join(labels, ", "), len(errs))
+	return filled, errors.Join(errs...)
+}
+
+func summaryOptions(cfg *config.Config) summary.Options {
+	return summary.Options{MaxGap: cfg.SampleGap(), Buckets: cfg.Summary.Buckets}
+}
+
+// summaryOptions ditambah kalender jam operasional dari db untuk uptime.
+// Downtime terjadwal tetap dihitung 24 jam.
+func calendarOptions(cfg *config.Config, db *sql.DB, kind string) (summary.Options, error) {
+	opts := summaryOptions(cfg)
+	if kind == "downtime" {
+		return opts, nil
+	}
+	calendars, err := calendar.Load(db, cfg.Tables)
+	if err != nil {
+		return opts, err
+	}
+	opts.Calendars = calendars
+	return opts, nil
+}
+
+// Uptime meringkas sampel yang dihitung ke SLA, downtime meringkas sampel
+// pekerjaan terjadwal
+func summaryTarget(cfg *config.Config, set *rules.Set, kind string) (rules.Filter, string) {
+	if kind == "downtime" {
+		return rules.Filter{Set: set, Class: rules.Scheduled}, cfg.Tables.SummaryDowntime
+	}
+	return rules.Filter{Set: set, Class: rules.SLA}, cfg.Tables.SummaryUptime
+}
+
+// Fungsi yang meringkas satu jam kind ke tabel ringkasannya
+func hourWriter(cfg *config.Config, db *sql.DB, set *rules.Set, kind string) (func(hour time.Time) (int, error), string) {
+	filter, table := summaryTarget(cfg, set, kind)
+	return func(hour time.Time) (int, error) {
+		return writeHour(cfg, db, kind, filter, table, hour)
+	}, table
+}
+
+func writeHour(cfg *config.Config, db *sql.DB, kind string, filter rules.Filter, table string, hour time.Time) (int, error) {
+	nextHour := hour.Add(time.Hour)
+	fmt.Printf("Rentang waktu query: %s - %s\n", hour.Format(time.RFC3339), nextHour.Format(time.RFC3339))
+
+	// Sampel di dalam jendela maintenance otomatis jadi downtime terjadwal
+	schedule, err := maintenance.Load(db, cfg.Tables, cfg.Location(), hour.Add(-cfg.SampleGap()), nextHour)
+	if err != nil {
+		return 0, err
+	}
+	filter.Maintenance = schedule
+
+	opts, err := calendarOptions(cfg, db, kind)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := summary.Collect(db, cfg.Tables.PingResults, filter, hour, nextHour, opts)
+	if err != nil {
+		return 0, err
+	}
+	// Target tanpa sampel sama sekali tetap dapat baris (semua unknown)
+	if rows, err = summary.AddSilentTargets(db, cfg.Tables, filter, rows, hour, nextHour); err != nil {
+		return 0, err
+	}
+	rows = summary.ApplyCoverage(rows, hour, nextHour, cfg.Probe.Interval, cfg.Summary.MissingData, opts.Calendars)
+
+	if kind == "downtime" {
+		return len(rows), summary.WriteDowntime(db, table, hour, rows)
+	}
+	return len(rows), summary.WriteUptime(db, table, hour, rows)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/maintenance"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+	"sla_uptime/internal/upload"
+)
+
+// slauptime upload [uptime|downtime|raw]: ringkasan dari SQLite lokal (probe.sink: sqlite)
+// dikirim ke tabel uptime_summary di MySQL, atau dengan raw baris ping_results
+// mentah dikirim bertahap memakai watermark
+func runUpload(args []string) error {
+	kind, args := splitKind(args, "uptime")
+	if kind != "uptime" && kind != "downtime" && kind != "raw" {
+		return fmt.Errorf("pemakaian: slauptime upload [uptime|downtime|raw] [flag]")
+	}
+
+	cfg, err := loadConfig("upload", args, nil)
+	if err != nil {
+		return err
+	}
+
+	sqliteDB, err := store.OpenSQLite(cfg.SQLite.Path)
+	if err != nil {
+		return err
+	}
+	defer sqliteDB.Close()
+
+	if err := migrate.Check(sqliteDB, migrate.SQLite); err != nil {
+		return err
+	}
+
+	mysqlDB, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer mysqlDB.Close()
+
+	if err := migrate.Check(mysqlDB, migrate.MySQL); err != nil {
+		return err
+	}
+
+	if kind == "raw" {
+		return uploadRaw(cfg, sqliteDB, mysqlDB)
+	}
+
+	// Jam terakhir yang sudah selesai, dalam UTC seperti summarize
+	nextHour := time.Now().UTC().Truncate(time.Hour)
+	lastHour := nextHour.Add(-time.Hour)
+
+	fmt.Printf("Rentang waktu query: %s - %s\n", lastHour.Format(time.RFC3339), nextHour.Format(time.RFC3339))
+
+	set, err := loadRules(cfg, mysqlDB)
+	if err != nil {
+		return err
+	}
+	schedule, err := maintenance.Load(mysqlDB, cfg.Tables, cfg.Location(), lastHour.Add(-cfg.SampleGap()), nextHour)
+	if err != nil {
+		return err
+	}
+	filter := rules.Filter{Set: set, Class: rules.SLA, Maintenance: schedule}
+	if kind == "downtime" {
+		filter.Class = rules.Scheduled
+	}
+
+	opts, err := calendarOptions(cfg, mysqlDB, kind)
+	if err != nil {
+		return err
+	}
+	rows, err := summary.Collect(sqliteDB, cfg.Tables.PingResults, filter, lastHour, nextHour, opts)
+	if err != nil {
+		return err
+	}
+	rows = summary.ApplyCoverage(rows, lastHour, nextHour, cfg.Probe.Interval, cfg.Summary.MissingData, opts.Calendars)
+	return summary.WriteUptimeSummary(mysqlDB, cfg.Tables.UptimeSummary, rows)
+}
+
+// Kirim ping_results mentah sampai habis, lalu berhenti atau ulangi tiap upload.interval
+func uploadRaw(cfg *config.Config, sqliteDB, mysqlDB *sql.DB) error {
+	source := cfg.Upload.Source
+	if source == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("upload.source kosong dan hostname tidak bisa dibaca: %w", err)
+		}
+		source = hostname
+	}
+
+	for {
+		result, err := upload.Raw(sqliteDB, mysqlDB, migrate.MySQL, cfg.Tables, source, cfg.Upload.BatchSize, cfg.Upload.Prune)
+		if err != nil {
+			if cfg.Upload.Interval == 0 {
+				return err
+			}
+			// Mode jalan terus: coba lagi di putaran berikutnya, watermark tetap aman
+			log.Printf("Upload gagal, dicoba lagi dalam %v: %v", cfg.Upload.Interval, err)
+		} else {
+			if result.ResetFrom > 0 {
+				log.Printf("Upload %s: id lokal lebih kecil dari watermark %d (file SQLite dibuat ulang?), dikirim ulang dari awal", source, result.ResetFrom)
+			}
+			log.Printf("Upload %s: %d baris terkirim, %d baris lokal dihapus, watermark id %d",
+				source, result.Shipped, result.Pruned, result.Watermark)
+		}
+
+		if cfg.Upload.Interval == 0 {
+			return nil
+		}
+		time.Sleep(cfg.Upload.Interval)
+	}
+}
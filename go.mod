@@ -2,12 +2,16 @@ module sla_uptime
 
 go 1.23.0
 
+require (
+	github.com/go-ping/ping v1.2.0
+	github.com/go-sql-driver/mysql v1.8.1
+	github.com/google/uuid v1.2.0
+	github.com/mattn/go-sqlite3 v1.14.24
+	gopkg.in/yaml.v3 v3.0.1
+)
+
 require (
 	filippo.io/edwards25519 v1.1.0 // indirect
-	github.com/go-ping/ping v1.2.0 // indirect
-	github.com/go-sql-driver/mysql v1.8.1 // indirect
-	github.com/google/uuid v1.2.0 // indirect
-	github.com/mattn/go-sqlite3 v1.14.24 // indirect
 	golang.org/x/net v0.0.0-20210316092652-d523dce5a7f4 // indirect
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
 	golang.org/x/sys v0.0.0-20210315160823-c6e025ad8005 // indirect
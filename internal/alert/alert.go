@@ -0,0 +1,185 @@
+// Package alert menghitung burn rate error budget bulanan per target dengan
+// dua jendela (pendek dan panjang) dan mencatat alert di tabel sla_alerts.
+//
+// Burn rate adalah laju budget terbakar dibanding laju yang membuat budget
+// habis tepat di akhir periode: burn rate 1 berarti budget habis di akhir
+// bulan, burn rate 6 berarti habis dalam seperenam bulan. Alert aktif jika
+// burn rate kedua jendela mencapai ambang, jadi gangguan sesaat (hanya jendela
+// pendek) maupun gangguan yang sudah pulih (hanya jendela panjang) tidak
+// memicu alert.
+package alert
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"sla_uptime/internal/summary"
+)
+
+// Status event alert
+const (
+	Firing   = "firing"
+	Resolved = "resolved"
+)
+
+// BurnRate menghitung burn rate dari ringkasan satu jendela (sudah melalui
+// summary.ApplyCoverage) terhadap target (persen). Waktu unknown dihitung
+// sesuai kebijakan missing_data seperti uptime.
+func BurnRate(row summary.Row, target float64, policy string) float64 {
+	budget := (100 - target) / 100
+	if budget <= 0 {
+		return 0
+	}
+	bad, total := row.DownSeconds, row.UpSeconds+row.DownSeconds
+	switch policy {
+	case summary.MissingDown:
+		bad += row.UnknownSeconds
+		total += row.UnknownSeconds
+	case summary.MissingUp:
+		total += row.UnknownSeconds
+	}
+	if total == 0 {
+		return 0
+	}
+	return bad / total / budget
+}
+
+// Evaluation adalah burn rate satu target pada satu waktu evaluasi
+type Evaluation struct {
+	IPID   int
+	Target float64 // persen
+	Short  float64 // burn rate jendela pendek
+	Long   float64 // burn rate jendela panjang
+}
+
+// Firing mengembalikan true jika burn rate kedua jendela mencapai threshold
+func (e Evaluation) Firing(threshold float64) bool {
+	return e.Short >= threshold && e.Long >= threshold
+}
+
+// Alert adalah satu baris sla_alerts
+type Alert struct {
+	ID         int64
+	IPID       int
+	Target     float64
+	ShortBurn  float64
+	LongBurn   float64
+	Threshold  float64
+	FiredAt    time.Time
+	ResolvedAt sql.NullTime
+}
+
+// Event adalah perubahan status alert yang perlu dilaporkan
+type Event struct {
+	Status string // Firing atau Resolved
+	Alert  Alert
+}
+
+// Plan adalah perubahan alert hasil Decide
+type Plan struct {
+	Fire    []Alert // alert baru, ID masih 0
+	Update  []Alert // alert terbuka yang masih di atas ambang, burn rate terbaru
+	Resolve []Alert // alert yang selesai, burn rate terakhir dan ResolvedAt terisi
+}
+
+// Decide mencocokkan hasil evaluasi dengan alert yang masih terbuka (per
+// ip_id): target yang melewati ambang dan belum punya alert mendapat alert
+// baru, alert yang sudah terbuka diperbarui burn rate-nya, dan alert target
+// yang dievaluasi dan sudah di bawah ambang ditutup. Alert target yang tidak
+// dievaluasi (misalnya di luar jam operasional atau targetnya dihapus)
+// dibiarkan terbuka.
+func Decide(open map[int]Alert, evals []Evaluation, threshold float64, now time.Time) Plan {
+	var plan Plan
+	for _, e := range evals {
+		a, ok := open[e.IPID]
+		switch {
+		case e.Firing(threshold) && ok:
+			a.ShortBurn, a.LongBurn = e.Short, e.Long
+			plan.Update = append(plan.Update, a)
+		case e.Firing(threshold):
+			plan.Fire = append(plan.Fire, Alert{IPID: e.IPID, Target: e.Target, ShortBurn: e.Short, LongBurn: e.Long, Threshold: threshold, FiredAt: now})
+		case ok:
+			a.ShortBurn, a.LongBurn = e.Short, e.Long
+			a.ResolvedAt = sql.NullTime{Time: now, Valid: true}
+			plan.Resolve = append(plan.Resolve, a)
+		}
+	}
+	return plan
+}
+
+// Apply menjalankan Decide terhadap alert yang masih terbuka dan menyimpan
+// hasilnya dalam satu transaksi. Mengembalikan alert yang baru aktif dan yang
+// baru selesai.
+func Apply(db *sql.DB, table string, evals []Evaluation, threshold float64, now time.Time) ([]Event, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback()
+
+	open, err := loadOpen(tx, table)
+	if err != nil {
+		return nil, err
+	}
+	plan := Decide(open, evals, threshold, now)
+
+	var events []Event
+	for _, a := range plan.Fire {
+		result, err := tx.Exec(`
+            INSERT INTO `+table+` (ip_id, target_percentage, short_burn_rate, long_burn_rate, threshold, fired_at, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?)
+        `, a.IPID, a.Target, a.ShortBurn, a.LongBurn, a.Threshold, a.FiredAt, now)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menyimpan alert ke %s: %w", table, err)
+		}
+		if a.ID, err = result.LastInsertId(); err != nil {
+			return nil, fmt.Errorf("gagal membaca id alert: %w", err)
+		}
+		events = append(events, Event{Status: Firing, Alert: a})
+	}
+	for _, a := range plan.Update {
+		_, err := tx.Exec("UPDATE "+table+" SET short_burn_rate = ?, long_burn_rate = ?, updated_at = ? WHERE id = ?",
+			a.ShortBurn, a.LongBurn, now, a.ID)
+		if err != nil {
+			return nil, fmt.Errorf("gagal memperbarui alert di %s: %w", table, err)
+		}
+	}
+	for _, a := range plan.Resolve {
+		_, err := tx.Exec("UPDATE "+table+" SET short_burn_rate = ?, long_burn_rate = ?, resolved_at = ?, updated_at = ? WHERE id = ?",
+			a.ShortBurn, a.LongBurn, now, now, a.ID)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menutup alert di %s: %w", table, err)
+		}
+		events = append(events, Event{Status: Resolved, Alert: a})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return events, nil
+}
+
+// Alert yang masih terbuka per ip_id
+func loadOpen(tx *sql.Tx, table string) (map[int]Alert, error) {
+	rows, err := tx.Query(`
+        SELECT id, ip_id, target_percentage, short_burn_rate, long_burn_rate, threshold, fired_at
+        FROM ` + table + `
+        WHERE resolved_at IS NULL
+        FOR UPDATE
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca alert terbuka dari %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	open := make(map[int]Alert)
+	for rows.Next() {
+		var a Alert
+		if err := rows.Scan(&a.ID, &a.IPID, &a.Target, &a.ShortBurn, &a.LongBurn, &a.Threshold, &a.FiredAt); err != nil {
+			return nil, fmt.Errorf("gagal membaca alert terbuka dari %s: %w", table, err)
+		}
+		open[a.IPID] = a
+	}
+	return open, rows.Err()
+}
@@ -0,0 +1,202 @@
+package alert_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/alert"
+	"sla_uptime/internal/summary"
+	"sla_uptime/internal/testdb"
+)
+
+func TestBurnRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		row    summary.Row
+		target float64
+		policy string
+		want   float64
+	}{
+		{"tanpa downtime", summary.Row{UpSeconds: 3600}, 99.9, summary.MissingExcluded, 0},
+		{"laju pas habis di akhir periode", summary.Row{UpSeconds: 3596.4, DownSeconds: 3.6}, 99.9, summary.MissingExcluded, 1},
+		{"down penuh", summary.Row{DownSeconds: 3600}, 99, summary.MissingExcluded, 100},
+		{"unknown diabaikan", summary.Row{UpSeconds: 1800, DownSeconds: 18, UnknownSeconds: 1782}, 99, summary.MissingExcluded, 18.0 / 1818 / 0.01},
+		{"unknown dihitung down", summary.Row{UpSeconds: 1800, DownSeconds: 18, UnknownSeconds: 1782}, 99, summary.MissingDown, 1800.0 / 3600 / 0.01},
+		{"unknown dihitung up", summary.Row{UpSeconds: 1800, DownSeconds: 18, UnknownSeconds: 1782}, 99, summary.MissingUp, 18.0 / 3600 / 0.01},
+		{"tanpa waktu terukur", summary.Row{UnknownSeconds: 3600}, 99, summary.MissingExcluded, 0},
+		{"target 100 tanpa budget", summary.Row{DownSeconds: 3600}, 100, summary.MissingExcluded, 0},
+	}
+	for _, tt := range tests {
+		if got := alert.BurnRate(tt.row, tt.target, tt.policy); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: BurnRate = %v, seharusnya %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluationFiring(t *testing.T) {
+	tests := []struct {
+		short, long float64
+		want        bool
+	}{
+		{14.4, 14.4, true},
+		{20, 15, true},
+		{20, 1, false}, // gangguan sesaat
+		{1, 20, false}, // sudah pulih
+		{0, 0, false},
+	}
+	for _, tt := range tests {
+		e := alert.Evaluation{Short: tt.short, Long: tt.long}
+		if got := e.Firing(14.4); got != tt.want {
+			t.Errorf("Firing(short %v, long %v) = %v, seharusnya %v", tt.short, tt.long, got, tt.want)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	const threshold = 6
+	now := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	eval := func(ipID int, short, long float64) alert.Evaluation {
+		return alert.Evaluation{IPID: ipID, Target: 99.9, Short: short, Long: long}
+	}
+	open := func(ipIDs ...int) map[int]alert.Alert {
+		m := make(map[int]alert.Alert)
+		for _, ipID := range ipIDs {
+			m[ipID] = alert.Alert{ID: int64(100 + ipID), IPID: ipID, Target: 99.9, ShortBurn: 7, LongBurn: 7, Threshold: threshold}
+		}
+		return m
+	}
+	tests := []struct {
+		name                  string
+		open                  map[int]alert.Alert
+		evals                 []alert.Evaluation
+		fire, update, resolve []int
+	}{
+		{"alert baru", open(), []alert.Evaluation{eval(1, 10, 8), eval(2, 10, 1), eval(3, 6, 6)}, []int{1, 3}, nil, nil},
+		{"masih di atas ambang", open(1), []alert.Evaluation{eval(1, 12, 9)}, nil, []int{1}, nil},
+		{"jendela pendek turun", open(1), []alert.Evaluation{eval(1, 2, 9)}, nil, nil, []int{1}},
+		{"jendela panjang turun", open(1), []alert.Evaluation{eval(1, 9, 2)}, nil, nil, []int{1}},
+		{"di bawah ambang tanpa alert", open(), []alert.Evaluation{eval(1, 2, 9)}, nil, nil, nil},
+		// ip_id 3 tidak dievaluasi (misalnya di luar jam operasional)
+		{"tidak dievaluasi tetap terbuka", open(1, 3), []alert.Evaluation{eval(1, 0, 0)}, nil, nil, []int{1}},
+		{"tanpa evaluasi", open(1, 2), nil, nil, nil, nil},
+	}
+	ipIDs := func(alerts []alert.Alert) []int {
+		var ids []int
+		for _, a := range alerts {
+			ids = append(ids, a.IPID)
+		}
+		return ids
+	}
+	for _, tt := range tests {
+		plan := alert.Decide(tt.open, tt.evals, threshold, now)
+		if !sameIDs(ipIDs(plan.Fire), tt.fire) || !sameIDs(ipIDs(plan.Update), tt.update) || !sameIDs(ipIDs(plan.Resolve), tt.resolve) {
+			t.Errorf("%s: baru %v, diperbarui %v, selesai %v, seharusnya %v, %v, %v",
+				tt.name, ipIDs(plan.Fire), ipIDs(plan.Update), ipIDs(plan.Resolve), tt.fire, tt.update, tt.resolve)
+			continue
+		}
+		for _, a := range plan.Fire {
+			if a.ID != 0 || !a.FiredAt.Equal(now) || a.Threshold != threshold || a.ResolvedAt.Valid {
+				t.Errorf("%s: alert baru %+v", tt.name, a)
+			}
+		}
+		for _, a := range append(plan.Update, plan.Resolve...) {
+			e := tt.evals[0]
+			if a.ID != tt.open[a.IPID].ID || a.ShortBurn != e.Short || a.LongBurn != e.Long {
+				t.Errorf("%s: alert %+v, seharusnya id %d dengan burn rate %v / %v", tt.name, a, tt.open[a.IPID].ID, e.Short, e.Long)
+			}
+		}
+		for _, a := range plan.Resolve {
+			if !a.ResolvedAt.Valid || !a.ResolvedAt.Time.Equal(now) {
+				t.Errorf("%s: alert selesai tanpa resolved_at %+v", tt.name, a)
+			}
+		}
+	}
+}
+
+// Keputusan ada di TestDecide, di sini hanya penyimpanannya di sla_alerts
+func TestApply(t *testing.T) {
+	db, cfg := testdb.MySQL(t)
+	table := cfg.Tables.SLAAlerts
+	const threshold = 6
+	now := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	type step struct {
+		evals    []alert.Evaluation
+		firing   []int // ip_id yang baru aktif
+		resolved []int // ip_id yang baru selesai
+		open     int
+	}
+	eval := func(ipID int, short, long float64) alert.Evaluation {
+		return alert.Evaluation{IPID: ipID, Target: 99.9, Short: short, Long: long}
+	}
+	steps := []step{
+		{evals: []alert.Evaluation{eval(1, 10, 8), eval(2, 10, 1), eval(3, 7, 7)}, firing: []int{1, 3}, open: 2},
+		// ip_id 1 dan 3 masih di atas ambang: alert yang sama diperbarui tanpa event
+		{evals: []alert.Evaluation{eval(1, 12, 9), eval(2, 10, 7), eval(3, 7, 6)}, firing: []int{2}, open: 3},
+		// ip_id 1 turun di bawah ambang, alert ip_id 3 yang tidak dievaluasi tetap terbuka
+		{evals: []alert.Evaluation{eval(1, 2, 9), eval(2, 10, 7)}, resolved: []int{1}, open: 2},
+		// ip_id 1 melewati ambang lagi: alert baru, bukan membuka yang lama
+		{evals: []alert.Evaluation{eval(1, 8, 8), eval(2, 0, 0), eval(3, 1, 1)}, firing: []int{1}, resolved: []int{2, 3}, open: 1},
+	}
+
+	for i, s := range steps {
+		at := now.Add(time.Duration(i) * 5 * time.Minute)
+		events, err := alert.Apply(db, table, s.evals, threshold, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var firing, resolved []int
+		for _, e := range events {
+			switch e.Status {
+			case alert.Firing:
+				firing = append(firing, e.Alert.IPID)
+				if e.Alert.ID == 0 {
+					t.Errorf("langkah %d: alert baru tanpa id %+v", i, e.Alert)
+				}
+			case alert.Resolved:
+				resolved = append(resolved, e.Alert.IPID)
+			}
+		}
+		if !sameIDs(firing, s.firing) || !sameIDs(resolved, s.resolved) {
+			t.Errorf("langkah %d: aktif %v selesai %v, seharusnya %v dan %v", i, firing, resolved, s.firing, s.resolved)
+		}
+
+		var open int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table + " WHERE resolved_at IS NULL").Scan(&open); err != nil {
+			t.Fatal(err)
+		}
+		if open != s.open {
+			t.Errorf("langkah %d: %d alert terbuka, seharusnya %d", i, open, s.open)
+		}
+	}
+
+	// Burn rate alert terbuka ikut diperbarui, alert yang selesai menyimpan
+	// burn rate terakhirnya
+	var short, long float64
+	if err := db.QueryRow("SELECT short_burn_rate, long_burn_rate FROM "+table+" WHERE ip_id = 1 AND resolved_at IS NOT NULL").Scan(&short, &long); err != nil {
+		t.Fatal(err)
+	}
+	if short != 2 || long != 9 {
+		t.Errorf("alert ip_id 1 yang selesai: burn rate %v / %v, seharusnya 2 / 9", short, long)
+	}
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE ip_id = ?", 1).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Errorf("%d alert untuk ip_id 1, seharusnya 2", total)
+	}
+}
+
+func sameIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Webhook mengirim setiap Event sebagai POST JSON ke satu URL
+type Webhook struct {
+	URL    string
+	client *http.Client
+}
+
+// NewWebhook membuat Webhook, nil jika url kosong
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	if url == "" {
+		return nil
+	}
+	return &Webhook{URL: url, client: &http.Client{Timeout: timeout}}
+}
+
+type payload struct {
+	Status     string     `json:"status"`
+	AlertID    int64      `json:"alert_id"`
+	IPID       int        `json:"ip_id"`
+	Target     float64    `json:"target_percentage"`
+	ShortBurn  float64    `json:"short_burn_rate"`
+	LongBurn   float64    `json:"long_burn_rate"`
+	Threshold  float64    `json:"threshold"`
+	FiredAt    time.Time  `json:"fired_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Send mengirim satu event, status selain 2xx dianggap gagal
+func (w *Webhook) Send(e Event) error {
+	if w == nil {
+		return nil
+	}
+	p := payload{
+		Status:    e.Status,
+		AlertID:   e.Alert.ID,
+		IPID:      e.Alert.IPID,
+		Target:    e.Alert.Target,
+		ShortBurn: e.Alert.ShortBurn,
+		LongBurn:  e.Alert.LongBurn,
+		Threshold: e.Alert.Threshold,
+		FiredAt:   e.Alert.FiredAt,
+	}
+	if e.Alert.ResolvedAt.Valid {
+		p.ResolvedAt = &e.Alert.ResolvedAt.Time
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("gagal membuat payload webhook: %w", err)
+	}
+
+	resp, err := w.client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("gagal mengirim webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook membalas %s", resp.Status)
+	}
+	return nil
+}
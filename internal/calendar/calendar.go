@@ -0,0 +1,196 @@
+// Package calendar membaca kalender jam operasional (jam mingguan, hari
+// libur dan zona waktu) dari tabel sla_calendars dan menghitung berapa lama
+// satu rentang waktu termasuk jam operasional. Target yang punya kalender
+// (ip_monitor.calendar_name) hanya dihitung SLA-nya di dalam jam operasional,
+// target tanpa kalender dihitung 24 jam.
+package calendar
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/target"
+)
+
+// Calendar adalah satu baris sla_calendars beserta hari liburnya
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Hours    string // spesifikasi asli, lihat ParseHours
+
+	weekly   week
+	holidays map[string]bool // tanggal di zona waktu kalender, format 2006-01-02
+}
+
+// New membuat kalender dari nama zona waktu (IANA, contoh Asia/Jakarta, atau
+// Local) dan jam operasional mingguan
+func New(name, timezone, hours string) (*Calendar, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("zona waktu %q tidak dikenal: %w", timezone, err)
+	}
+	weekly, err := ParseHours(hours)
+	if err != nil {
+		return nil, err
+	}
+	return &Calendar{Name: name, Location: loc, Hours: hours, weekly: weekly, holidays: make(map[string]bool)}, nil
+}
+
+// AddHoliday menandai tanggal (di zona waktu kalender) sebagai hari libur,
+// sepanjang hari itu tidak termasuk jam operasional
+func (c *Calendar) AddHoliday(year int, month time.Month, day int) {
+	c.holidays[time.Date(year, month, day, 0, 0, 0, 0, c.Location).Format("2006-01-02")] = true
+}
+
+// Holidays mengembalikan jumlah hari libur kalender
+func (c *Calendar) Holidays() int {
+	return len(c.holidays)
+}
+
+// Covers mengembalikan true jika at termasuk jam operasional. Kalender nil
+// berarti 24 jam.
+func (c *Calendar) Covers(at time.Time) bool {
+	if c == nil {
+		return true
+	}
+	covered := false
+	c.each(at, at.Add(time.Nanosecond), func(start, end time.Time) { covered = true })
+	return covered
+}
+
+// Covered mengembalikan lama bagian [from, to) yang termasuk jam operasional
+func (c *Calendar) Covered(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	if c == nil {
+		return to.Sub(from)
+	}
+	var total time.Duration
+	c.each(from, to, func(start, end time.Time) { total += end.Sub(start) })
+	return total
+}
+
+// Memanggil fn untuk setiap bagian jam operasional yang beririsan dengan
+// [from, to). Jam dihitung per tanggal di zona waktu kalender, jadi
+// perpindahan DST ikut diperhitungkan.
+func (c *Calendar) each(from, to time.Time, fn func(start, end time.Time)) {
+	f := from.In(c.Location)
+	for day := time.Date(f.Year(), f.Month(), f.Day(), 0, 0, 0, 0, c.Location); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if c.holidays[day.Format("2006-01-02")] {
+			continue
+		}
+		for _, s := range c.weekly[day.Weekday()] {
+			start := time.Date(day.Year(), day.Month(), day.Day(), 0, s.start, 0, 0, c.Location)
+			end := time.Date(day.Year(), day.Month(), day.Day(), 0, s.end, 0, 0, c.Location)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				fn(start, end)
+			}
+		}
+	}
+}
+
+// Set berisi kalender setiap target yang punya kalender
+type Set struct {
+	calendars map[string]*Calendar
+	byIP      map[int]*Calendar
+}
+
+// For mengembalikan kalender ipID, nil (24 jam) jika tidak punya
+func (s *Set) For(ipID int) *Calendar {
+	if s == nil {
+		return nil
+	}
+	return s.byIP[ipID]
+}
+
+// Calendars mengembalikan semua kalender yang valid berdasarkan nama
+func (s *Set) Calendars() map[string]*Calendar {
+	if s == nil {
+		return nil
+	}
+	return s.calendars
+}
+
+// Load membaca sla_calendars, sla_calendar_holidays dan calendar_name di
+// ip_monitor. Kalender yang tidak valid dilewati dengan log, targetnya
+// dihitung 24 jam, supaya satu baris salah tidak menghentikan summarize.
+func Load(db *sql.DB, tables config.TablesConfig) (*Set, error) {
+	s := &Set{calendars: make(map[string]*Calendar), byIP: make(map[int]*Calendar)}
+
+	rows, err := db.Query("SELECT name, timezone, hours FROM " + tables.SLACalendars)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s: %w", tables.SLACalendars, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, timezone, hours string
+		if err := rows.Scan(&name, &timezone, &hours); err != nil {
+			return nil, fmt.Errorf("gagal membaca %s: %w", tables.SLACalendars, err)
+		}
+		c, err := New(name, timezone, hours)
+		if err != nil {
+			log.Printf("Kalender %q dilewati: %v", name, err)
+			continue
+		}
+		s.calendars[name] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.calendars) == 0 {
+		return s, nil
+	}
+
+	if err := s.loadHolidays(db, tables.SLACalendarHolidays); err != nil {
+		return nil, err
+	}
+
+	names, err := target.Calendars(db, tables.IPMonitor)
+	if err != nil {
+		return nil, err
+	}
+	for ipID, name := range names {
+		c, ok := s.calendars[name]
+		if !ok {
+			log.Printf("Kalender %q untuk ip_id %d tidak ada atau tidak valid, dihitung 24 jam", name, ipID)
+			continue
+		}
+		s.byIP[ipID] = c
+	}
+	return s, nil
+}
+
+func (s *Set) loadHolidays(db *sql.DB, table string) error {
+	rows, err := db.Query("SELECT calendar_name, DATE_FORMAT(holiday, '%Y-%m-%d') FROM " + table)
+	if err != nil {
+		return fmt.Errorf("gagal membaca %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, date string
+		if err := rows.Scan(&name, &date); err != nil {
+			return fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		c, ok := s.calendars[name]
+		if !ok {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		c.AddHoliday(day.Year(), day.Month(), day.Day())
+	}
+	return rows.Err()
+}
@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string // jam per hari, sun..sat
+		err  bool
+	}{
+		{spec: "mon-fri 08:00-17:00",
+			want: "[[] [{480 1020}] [{480 1020}] [{480 1020}] [{480 1020}] [{480 1020}] []]"},
+		{spec: "mon-fri 08:00-12:00,13:00-17:00; sat 08:00-12:00",
+			want: "[[] [{480 720} {780 1020}] [{480 720} {780 1020}] [{480 720} {780 1020}] [{480 720} {780 1020}] [{480 720} {780 1020}] [{480 720}]]"},
+		{spec: "sun 00:00-24:00",
+			want: "[[{0 1440}] [] [] [] [] [] []]"},
+		// lewat tengah malam: sisa jam masuk hari berikutnya, sat lanjut ke sun
+		{spec: "fri,sat 22:00-06:00",
+			want: "[[{0 360}] [] [] [] [] [{1320 1440}] [{0 360} {1320 1440}]]"},
+		{spec: "mon 22:00-00:00",
+			want: "[[] [{1320 1440}] [] [] [] [] []]"},
+		// rentang hari melewati minggu dan bagian yang bertumpuk digabung
+		{spec: "sat-mon 08:00-12:00; sun 10:00-14:00",
+			want: "[[{480 840}] [{480 720}] [] [] [] [] [{480 720}]]"},
+		{spec: "MON 08:00-09:00",
+			want: "[[] [{480 540}] [] [] [] [] []]"},
+		{spec: "mon-fri", err: true},
+		{spec: "xyz 08:00-17:00", err: true},
+		{spec: "mon 08:00", err: true},
+		{spec: "mon 08:00-08:00", err: true},
+		{spec: "mon 24:00-06:00", err: true},
+		{spec: "mon 08:60-09:00", err: true},
+		{spec: "mon 25:00-26:00", err: true},
+	}
+	for _, tt := range tests {
+		w, err := ParseHours(tt.spec)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseHours(%q): error %v, seharusnya error = %v", tt.spec, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if got := fmt.Sprint(w); got != tt.want {
+			t.Errorf("ParseHours(%q) = %s, seharusnya %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestCovered(t *testing.T) {
+	office, err := New("kantor", "Asia/Jakarta", "mon-fri 08:00-17:00; sat 22:00-02:00")
+	if err != nil {
+		t.Skipf("zona Asia/Jakarta tidak tersedia: %v", err)
+	}
+	office.AddHoliday(2024, time.January, 10)
+	loc := office.Location
+
+	// 2024-01-08 hari Senin
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, loc)
+	}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+	}{
+		{"satu hari kerja penuh", at(8, 0, 0), at(9, 0, 0), 9 * time.Hour},
+		{"sebagian jam kerja", at(8, 16, 0), at(8, 18, 0), time.Hour},
+		{"di luar jam kerja", at(8, 18, 0), at(8, 23, 0), 0},
+		{"hari libur", at(10, 0, 0), at(11, 0, 0), 0},
+		{"satu minggu", at(8, 0, 0), at(15, 0, 0), 4*9*time.Hour + 4*time.Hour},
+		{"malam Sabtu sampai Minggu dini hari", at(13, 23, 0), at(14, 1, 30), 150 * time.Minute},
+		{"sisa Minggu dini hari saja", at(14, 1, 0), at(14, 3, 0), time.Hour},
+		{"rentang kosong", at(8, 10, 0), at(8, 10, 0), 0},
+		{"rentang terbalik", at(8, 11, 0), at(8, 10, 0), 0},
+		{"rentang dalam UTC", at(8, 8, 0).UTC(), at(8, 9, 0).UTC(), time.Hour},
+	}
+	for _, tt := range tests {
+		if got := office.Covered(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: Covered = %s, seharusnya %s", tt.name, got, tt.want)
+		}
+	}
+
+	covers := []struct {
+		at   time.Time
+		want bool
+	}{
+		{at(8, 8, 0), true},
+		{at(8, 17, 0), false},
+		{at(8, 7, 59), false},
+		{at(10, 9, 0), false},
+		{at(14, 1, 59), true},
+	}
+	for _, tt := range covers {
+		if got := office.Covers(tt.at); got != tt.want {
+			t.Errorf("Covers(%s) = %v, seharusnya %v", tt.at.Format("Mon 2006-01-02 15:04"), got, tt.want)
+		}
+	}
+}
+
+// Kalender nil berarti 24 jam
+func TestCoveredNil(t *testing.T) {
+	var c *Calendar
+	from := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	if got := c.Covered(from, from.Add(36*time.Hour)); got != 36*time.Hour {
+		t.Errorf("Covered kalender nil = %s, seharusnya 36h", got)
+	}
+	if !c.Covers(from) {
+		t.Errorf("kalender nil seharusnya selalu Covers")
+	}
+	var s *Set
+	if s.For(1) != nil {
+		t.Errorf("Set nil seharusnya tanpa kalender")
+	}
+}
@@ -0,0 +1,139 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bagian jam operasional dalam satu hari, menit dari tengah malam [start, end)
+type span struct {
+	start, end int
+}
+
+// Jam operasional per hari, index = time.Weekday
+type week [7][]span
+
+var dayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// ParseHours membaca jam operasional mingguan, contoh
+// "mon-fri 08:00-17:00; sat 08:00-12:00". Setiap bagian dipisah titik koma:
+// daftar hari (mon..sun, rentang mon-fri, daftar sat,sun) lalu satu atau
+// lebih rentang jam dipisah koma. Jam akhir 24:00 berarti sampai tengah
+// malam, dan jam akhir sebelum jam mulai (22:00-06:00) berlanjut ke hari
+// berikutnya.
+func ParseHours(spec string) (week, error) {
+	var w week
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			return w, fmt.Errorf("jam operasional %q harus berisi hari dan jam, contoh \"mon-fri 08:00-17:00\"", part)
+		}
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return w, fmt.Errorf("jam operasional %q: %w", part, err)
+		}
+		for _, r := range strings.Split(fields[1], ",") {
+			start, end, err := parseRange(r)
+			if err != nil {
+				return w, fmt.Errorf("jam operasional %q: %w", part, err)
+			}
+			for _, d := range days {
+				if end > start {
+					w[d] = append(w[d], span{start, end})
+					continue
+				}
+				// Lewat tengah malam, sisanya masuk hari berikutnya
+				w[d] = append(w[d], span{start, 24 * 60})
+				if end > 0 {
+					next := (d + 1) % 7
+					w[next] = append(w[next], span{0, end})
+				}
+			}
+		}
+	}
+	for d := range w {
+		w[d] = merge(w[d])
+	}
+	return w, nil
+}
+
+func parseDays(field string) ([]int, error) {
+	var days []int
+	for _, item := range strings.Split(strings.ToLower(field), ",") {
+		from, to := item, item
+		if i := strings.Index(item, "-"); i >= 0 {
+			from, to = item[:i], item[i+1:]
+		}
+		first, ok := dayNames[from]
+		if !ok {
+			return nil, fmt.Errorf("hari %q tidak dikenal, pakai mon tue wed thu fri sat sun", from)
+		}
+		last, ok := dayNames[to]
+		if !ok {
+			return nil, fmt.Errorf("hari %q tidak dikenal, pakai mon tue wed thu fri sat sun", to)
+		}
+		// Rentang boleh melewati minggu, contoh fri-mon
+		for d := first; ; d = (d + 1) % 7 {
+			days = append(days, d)
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// Rentang "HH:MM-HH:MM" menjadi menit dari tengah malam
+func parseRange(r string) (int, int, error) {
+	i := strings.Index(r, "-")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("rentang jam %q harus berbentuk HH:MM-HH:MM", r)
+	}
+	start, err := parseClock(r[:i])
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseClock(r[i+1:])
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == 24*60 || start == end {
+		return 0, 0, fmt.Errorf("rentang jam %q kosong", r)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("jam %q harus berbentuk HH:MM", s)
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	m, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("jam %q tidak valid", s)
+	}
+	return h*60 + m, nil
+}
+
+// Mengurutkan dan menggabungkan bagian yang bertumpuk supaya tidak dihitung dua kali
+func merge(spans []span) []span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var out []span
+	for _, s := range spans {
+		if n := len(out); n > 0 && s.start <= out[n-1].end {
+			if s.end > out[n-1].end {
+				out[n-1].end = s.end
+			}
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,468 @@
+// Package config berisi konfigurasi bersama untuk semua program sla_uptime:
+// koneksi database, pengaturan probe, nama tabel dan aturan filter.
+//
+// Urutan prioritas: nilai default < file YAML < environment variable < flag.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Config adalah seluruh konfigurasi yang dibaca saat program start
+type Config struct {
+	MySQL     MySQLConfig     `yaml:"mysql"`
+	SQLite    SQLiteConfig    `yaml:"sqlite"`
+	Probe     ProbeConfig     `yaml:"probe"`
+	Writer    WriterConfig    `yaml:"writer"`
+	Spool     SpoolConfig     `yaml:"spool"`
+	Upload    UploadConfig    `yaml:"upload"`
+	Summary   SummaryConfig   `yaml:"summary"`
+	Incidents IncidentsConfig `yaml:"incidents"`
+	Alerts    AlertsConfig    `yaml:"alerts"`
+	Report    ReportConfig    `yaml:"report"`
+	Migrate   MigrateConfig   `yaml:"migrate"`
+	Tables    TablesConfig    `yaml:"tables"`
+	Filters   FiltersConfig   `yaml:"filters"`
+}
+
+type MySQLConfig struct {
+	DSN             string        `yaml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+}
+
+type SQLiteConfig struct {
+	Path string `yaml:"path"`
+}
+
+type ProbeConfig struct {
+	Interval        time.Duration `yaml:"interval"`         // jeda antar siklus probe
+	RefreshInterval time.Duration `yaml:"refresh_interval"` // jeda baca ulang ip_monitor
+	Concurrency     int           `yaml:"concurrency"`
+	Mode            string        `yaml:"mode"` // auto, privileged, unprivileged, exec
+	Sink            string        `yaml:"sink"` // mysql atau sqlite (lokal, dikirim lewat upload)
+	Timeout         time.Duration `yaml:"timeout"`
+	Count           int           `yaml:"count"`           // jumlah paket per probe
+	PacketInterval  time.Duration `yaml:"packet_interval"` // jeda antar paket
+	MaxLoss         float64       `yaml:"max_loss"`        // packet loss (%) yang masih dianggap up
+	DownRetries     int           `yaml:"down_retries"`
+	RetryInterval   time.Duration `yaml:"retry_interval"`
+}
+
+// WriterConfig mengatur penulisan hasil probe secara batch
+type WriterConfig struct {
+	BatchSize     int           `yaml:"batch_size"`     // jumlah baris maksimum per INSERT
+	FlushInterval time.Duration `yaml:"flush_interval"` // batas waktu sebelum batch yang belum penuh ditulis
+	QueueSize     int           `yaml:"queue_size"`     // kapasitas antrian, probe menunggu jika penuh
+	StatsInterval time.Duration `yaml:"stats_interval"` // jeda log statistik writer, 0 = tidak di-log
+}
+
+// SpoolConfig mengatur antrian lokal saat MySQL tidak bisa dihubungi (probe.sink: mysql)
+type SpoolConfig struct {
+	Path          string        `yaml:"path"`           // file SQLite spool, kosong = spool mati
+	RetryInterval time.Duration `yaml:"retry_interval"` // jeda antar percobaan kirim ulang
+}
+
+// UploadConfig mengatur "slauptime upload raw" dari SQLite lokal ke MySQL
+type UploadConfig struct {
+	Source    string        `yaml:"source"`     // nama sumber untuk watermark, kosong = hostname
+	BatchSize int           `yaml:"batch_size"` // jumlah baris per transaksi
+	Prune     bool          `yaml:"prune"`      // hapus baris lokal yang sudah tersimpan di MySQL
+	Interval  time.Duration `yaml:"interval"`   // jeda antar upload, 0 = sekali jalan lalu berhenti
+}
+
+// SummaryConfig mengatur subcommand summarize
+type SummaryConfig struct {
+	CatchupLookback time.Duration `yaml:"catchup_lookback"`  // seberapa jauh ke belakang jam terlewat dicari, 0 = mati
+	MissingData     string        `yaml:"missing_data"`      // sampel yang tidak tercatat: excluded, up atau down
+	MaxSampleGap    time.Duration `yaml:"max_sample_gap"`    // lama maksimum satu sampel berlaku, 0 = 3 x probe.interval
+	Buckets         []float64     `yaml:"histogram_buckets"` // batas bucket histogram response time (ms), kosong = tanpa histogram
+}
+
+// IncidentsConfig mengatur "slauptime incidents build"
+type IncidentsConfig struct {
+	BatchSize  int           `yaml:"batch_size"`  // jumlah ping_results per transaksi
+	Interval   time.Duration `yaml:"interval"`    // jeda antar build, 0 = sekali jalan lalu berhenti
+	SettleTime time.Duration `yaml:"settle_time"` // lama maksimum INSERT ping_results sampai commit, id sebelum itu dibaca ulang
+}
+
+// AlertsConfig mengatur "slauptime alerts" (burn rate error budget bulanan)
+type AlertsConfig struct {
+	ShortWindow    time.Duration `yaml:"short_window"` // jendela pendek, memastikan budget masih terbakar
+	LongWindow     time.Duration `yaml:"long_window"`  // jendela panjang, memastikan bukan gangguan sesaat
+	BurnRate       float64       `yaml:"burn_rate"`    // alert jika burn rate kedua jendela minimal segini
+	Interval       time.Duration `yaml:"interval"`     // jeda antar evaluasi, 0 = sekali jalan lalu berhenti
+	WebhookURL     string        `yaml:"webhook_url"`  // POST JSON setiap alert aktif / selesai, kosong = hanya log
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+}
+
+// ReportConfig mengatur tampilan waktu ke pengguna. Database selalu UTC,
+// zona waktu ini hanya dipakai untuk membaca -from / -to, menampilkan waktu
+// dan mengevaluasi cron jendela maintenance.
+type ReportConfig struct {
+	Timezone string `yaml:"timezone"` // nama IANA (Asia/Jakarta), UTC, atau Local = zona waktu host
+}
+
+// Location mengembalikan zona waktu report.timezone, time.Local jika tidak valid
+// (Validate sudah menolak nilai yang tidak valid)
+func (c *Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Report.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// MigrateConfig mengatur migrasi yang butuh keterangan tentang data lama
+type MigrateConfig struct {
+	// Zona waktu timestamp ping_results lama untuk migrasi 0017: zona waktu
+	// server MySQL jika diisi async_mysql (DEFAULT CURRENT_TIMESTAMP), contoh
+	// Etc/GMT-7, atau host jika hanya ditulis slauptime probe (waktu lokal
+	// host). Kosong = migrasi ditolak kalau ping_results sudah berisi data.
+	PingResultsTimezone string `yaml:"ping_results_timezone"`
+}
+
+// Nilai migrate.ping_results_timezone untuk memakai zona waktu host
+const HostTimezone = "host"
+
+// SampleGap mengembalikan lama maksimum status satu sampel berlaku sebelum
+// dianggap unknown
+func (c *Config) SampleGap() time.Duration {
+	if c.Summary.MaxSampleGap > 0 {
+		return c.Summary.MaxSampleGap
+	}
+	return 3 * c.Probe.Interval
+}
+
+// Batas batch_size supaya satu INSERT tidak melewati 65535 placeholder MySQL
+const MaxBatchSize = 4500 // 4500 baris x 14 kolom = 63000 placeholder
+
+type TablesConfig struct {
+	IPMonitor       string `yaml:"ip_monitor"`
+	PingResults     string `yaml:"ping_results"`
+	SummaryUptime   string `yaml:"summary_uptime"`
+	SummaryDowntime string `yaml:"summary_downtime"`
+	UptimeSummary   string `yaml:"uptime_summary"`
+	UploadState     string `yaml:"upload_state"`
+	SummaryRuns     string `yaml:"summary_runs"`
+	SLARules        string `yaml:"sla_rules"`
+
+	MaintenanceWindows string `yaml:"maintenance_windows"`
+	Incidents          string `yaml:"incidents"`
+	IncidentState      string `yaml:"incident_state"`
+	IncidentTargets    string `yaml:"incident_targets"`
+	SLAKPI             string `yaml:"sla_kpi"`
+	SLATargets         string `yaml:"sla_targets"`
+	SLAAttainment      string `yaml:"sla_attainment"`
+	SLAAlerts          string `yaml:"sla_alerts"`
+	SLACalendars       string `yaml:"sla_calendars"`
+
+	SLACalendarHolidays string `yaml:"sla_calendar_holidays"`
+
+	SummaryUptimeDaily   string `yaml:"summary_uptime_daily"`
+	SummaryUptimeWeekly  string `yaml:"summary_uptime_weekly"`
+	SummaryUptimeMonthly string `yaml:"summary_uptime_monthly"`
+}
+
+// FilterRule memilih baris ping_results berdasarkan status_id dan reason_id.
+// Hanya dipakai jika tabel sla_rules kosong, lihat package rules.
+type FilterRule struct {
+	StatusIDs        []int `yaml:"status_ids"`
+	ExcludeReasonIDs []int `yaml:"exclude_reason_ids"`
+}
+
+type FiltersConfig struct {
+	Uptime   FilterRule `yaml:"uptime"`
+	Downtime FilterRule `yaml:"downtime"`
+}
+
+// Default mengembalikan konfigurasi yang sama dengan nilai yang dulu hardcode
+func Default() Config {
+	return Config{
+		MySQL: MySQLConfig{
+			DSN:             "user:pass@tcp(localhost:3306)/sla_uptime?parseTime=true&loc=UTC&timeout=30s&writeTimeout=30s&readTimeout=30s",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+		},
+		SQLite: SQLiteConfig{
+			Path: "../ping_results.db",
+		},
+		Probe: ProbeConfig{
+			Interval:        5 * time.Second,
+			RefreshInterval: 5 * time.Second,
+			Concurrency:     300,
+			Mode:            "auto",
+			Sink:            "mysql",
+			Timeout:         time.Second,
+			Count:           1,
+			PacketInterval:  200 * time.Millisecond,
+			MaxLoss:         50,
+			DownRetries:     0,
+			RetryInterval:   500 * time.Millisecond,
+		},
+		Writer: WriterConfig{
+			BatchSize:     500,
+			FlushInterval: time.Second,
+			QueueSize:     10000,
+			StatsInterval: time.Minute,
+		},
+		Spool: SpoolConfig{
+			Path:          "../ping_spool.db",
+			RetryInterval: 5 * time.Second,
+		},
+		Upload: UploadConfig{
+			BatchSize: 1000,
+			Prune:     true,
+		},
+		Summary: SummaryConfig{
+			CatchupLookback: 7 * 24 * time.Hour,
+			MissingData:     "excluded",
+			Buckets:         []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+		},
+		Incidents: IncidentsConfig{
+			BatchSize:  5000,
+			SettleTime: 5 * time.Minute,
+		},
+		Alerts: AlertsConfig{
+			ShortWindow:    time.Hour,
+			LongWindow:     6 * time.Hour,
+			BurnRate:       6,
+			WebhookTimeout: 10 * time.Second,
+		},
+		Report: ReportConfig{
+			Timezone: "Local",
+		},
+		Tables: TablesConfig{
+			IPMonitor:       "ip_monitor",
+			PingResults:     "ping_results",
+			SummaryUptime:   "summary_uptime",
+			SummaryDowntime: "summary_downtime",
+			UptimeSummary:   "uptime_summary",
+			UploadState:     "upload_state",
+			SummaryRuns:     "summary_runs",
+			SLARules:        "sla_rules",
+
+			MaintenanceWindows: "maintenance_windows",
+			Incidents:          "incidents",
+			IncidentState:      "incident_state",
+			IncidentTargets:    "incident_targets",
+			SLAKPI:             "sla_kpi",
+			SLATargets:         "sla_targets",
+			SLAAttainment:      "sla_attainment",
+			SLAAlerts:          "sla_alerts",
+			SLACalendars:       "sla_calendars",
+
+			SLACalendarHolidays: "sla_calendar_holidays",
+
+			SummaryUptimeDaily:   "summary_uptime_daily",
+			SummaryUptimeWeekly:  "summary_uptime_weekly",
+			SummaryUptimeMonthly: "summary_uptime_monthly",
+		},
+		Filters: FiltersConfig{
+			Uptime: FilterRule{
+				StatusIDs:        []int{7},
+				ExcludeReasonIDs: []int{16},
+			},
+			Downtime: FilterRule{
+				StatusIDs:        []int{8},
+				ExcludeReasonIDs: []int{16},
+			},
+		},
+	}
+}
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate mengecek semua nilai dan mengembalikan semua kesalahan sekaligus
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if strings.TrimSpace(c.MySQL.DSN) == "" {
+		fail("mysql.dsn wajib diisi")
+	} else if dsn, err := mysql.ParseDSN(c.MySQL.DSN); err != nil {
+		fail("mysql.dsn tidak valid: %v", err)
+	} else if !dsn.ParseTime {
+		fail("mysql.dsn harus memakai parseTime=true")
+	}
+	if c.MySQL.MaxOpenConns < 1 {
+		fail("mysql.max_open_conns minimal 1, didapat %d", c.MySQL.MaxOpenConns)
+	}
+	if c.MySQL.MaxIdleConns < 0 {
+		fail("mysql.max_idle_conns tidak boleh negatif, didapat %d", c.MySQL.MaxIdleConns)
+	}
+	if strings.TrimSpace(c.SQLite.Path) == "" {
+		fail("sqlite.path wajib diisi")
+	}
+
+	p := c.Probe
+	if p.Interval <= 0 {
+		fail("probe.interval harus lebih dari 0, didapat %s", p.Interval)
+	}
+	if p.RefreshInterval <= 0 {
+		fail("probe.refresh_interval harus lebih dari 0, didapat %s", p.RefreshInterval)
+	}
+	if p.Concurrency < 1 {
+		fail("probe.concurrency minimal 1, didapat %d", p.Concurrency)
+	}
+	switch p.Mode {
+	case "auto", "privileged", "unprivileged", "exec":
+	default:
+		fail("probe.mode harus auto, privileged, unprivileged atau exec, didapat %q", p.Mode)
+	}
+	switch p.Sink {
+	case "mysql", "sqlite":
+	default:
+		fail("probe.sink harus mysql atau sqlite, didapat %q", p.Sink)
+	}
+	if p.Timeout <= 0 {
+		fail("probe.timeout harus lebih dari 0, didapat %s", p.Timeout)
+	}
+	if p.Count < 1 {
+		fail("probe.count minimal 1, didapat %d", p.Count)
+	}
+	if p.Count > 1 && p.PacketInterval <= 0 {
+		fail("probe.packet_interval harus lebih dari 0 jika probe.count > 1, didapat %s", p.PacketInterval)
+	}
+	if p.MaxLoss < 0 || p.MaxLoss >= 100 {
+		fail("probe.max_loss harus 0 sampai kurang dari 100, didapat %v", p.MaxLoss)
+	}
+	if p.DownRetries < 0 {
+		fail("probe.down_retries tidak boleh negatif, didapat %d", p.DownRetries)
+	}
+	if p.DownRetries > 0 && p.RetryInterval < 0 {
+		fail("probe.retry_interval tidak boleh negatif, didapat %s", p.RetryInterval)
+	}
+
+	w := c.Writer
+	if w.BatchSize < 1 || w.BatchSize > MaxBatchSize {
+		fail("writer.batch_size harus 1 sampai %d, didapat %d", MaxBatchSize, w.BatchSize)
+	}
+	if w.FlushInterval <= 0 {
+		fail("writer.flush_interval harus lebih dari 0, didapat %s", w.FlushInterval)
+	}
+	if w.QueueSize < 1 {
+		fail("writer.queue_size minimal 1, didapat %d", w.QueueSize)
+	}
+	if w.StatsInterval < 0 {
+		fail("writer.stats_interval tidak boleh negatif, didapat %s", w.StatsInterval)
+	}
+
+	if c.Spool.Path != "" && c.Spool.RetryInterval <= 0 {
+		fail("spool.retry_interval harus lebih dari 0, didapat %s", c.Spool.RetryInterval)
+	}
+
+	if c.Upload.BatchSize < 1 || c.Upload.BatchSize > MaxBatchSize {
+		fail("upload.batch_size harus 1 sampai %d, didapat %d", MaxBatchSize, c.Upload.BatchSize)
+	}
+	if c.Upload.Interval < 0 {
+		fail("upload.interval tidak boleh negatif, didapat %s", c.Upload.Interval)
+	}
+
+	if c.Incidents.BatchSize < 1 {
+		fail("incidents.batch_size minimal 1, didapat %d", c.Incidents.BatchSize)
+	}
+	if c.Incidents.Interval < 0 {
+		fail("incidents.interval tidak boleh negatif, didapat %s", c.Incidents.Interval)
+	}
+	if c.Incidents.SettleTime < 0 {
+		fail("incidents.settle_time tidak boleh negatif, didapat %s", c.Incidents.SettleTime)
+	}
+
+	a := c.Alerts
+	if a.ShortWindow <= 0 || a.LongWindow <= a.ShortWindow {
+		fail("alerts.short_window harus lebih dari 0 dan kurang dari alerts.long_window, didapat %s dan %s", a.ShortWindow, a.LongWindow)
+	}
+	if a.BurnRate <= 0 {
+		fail("alerts.burn_rate harus lebih dari 0, didapat %v", a.BurnRate)
+	}
+	if a.Interval < 0 {
+		fail("alerts.interval tidak boleh negatif, didapat %s", a.Interval)
+	}
+	if a.WebhookURL != "" {
+		if u, err := url.Parse(a.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("alerts.webhook_url harus URL http atau https, didapat %q", a.WebhookURL)
+		}
+		if a.WebhookTimeout <= 0 {
+			fail("alerts.webhook_timeout harus lebih dari 0, didapat %s", a.WebhookTimeout)
+		}
+	}
+
+	if _, err := time.LoadLocation(c.Report.Timezone); err != nil {
+		fail("report.timezone tidak dikenal: %q", c.Report.Timezone)
+	}
+	if tz := c.Migrate.PingResultsTimezone; tz != "" && tz != HostTimezone {
+		if _, err := time.LoadLocation(tz); err != nil {
+			fail("migrate.ping_results_timezone tidak dikenal: %q", tz)
+		}
+	}
+
+	if c.Summary.CatchupLookback < 0 {
+		fail("summary.catchup_lookback tidak boleh negatif, didapat %s", c.Summary.CatchupLookback)
+	}
+	if c.Summary.MaxSampleGap < 0 {
+		fail("summary.max_sample_gap tidak boleh negatif, didapat %s", c.Summary.MaxSampleGap)
+	}
+	for i, b := range c.Summary.Buckets {
+		if b <= 0 || (i > 0 && b <= c.Summary.Buckets[i-1]) {
+			fail("summary.histogram_buckets harus positif dan urut naik, didapat %v", c.Summary.Buckets)
+			break
+		}
+	}
+	switch c.Summary.MissingData {
+	case "excluded", "up", "down":
+	default:
+		fail("summary.missing_data harus excluded, up atau down, didapat %q", c.Summary.MissingData)
+	}
+
+	for name, table := range map[string]string{
+		"tables.ip_monitor":       c.Tables.IPMonitor,
+		"tables.ping_results":     c.Tables.PingResults,
+		"tables.summary_uptime":   c.Tables.SummaryUptime,
+		"tables.summary_downtime": c.Tables.SummaryDowntime,
+		"tables.uptime_summary":   c.Tables.UptimeSummary,
+		"tables.upload_state":     c.Tables.UploadState,
+		"tables.summary_runs":     c.Tables.SummaryRuns,
+		"tables.sla_rules":        c.Tables.SLARules,
+
+		"tables.maintenance_windows": c.Tables.MaintenanceWindows,
+		"tables.incidents":           c.Tables.Incidents,
+		"tables.incident_state":      c.Tables.IncidentState,
+		"tables.incident_targets":    c.Tables.IncidentTargets,
+		"tables.sla_kpi":             c.Tables.SLAKPI,
+		"tables.sla_targets":         c.Tables.SLATargets,
+		"tables.sla_attainment":      c.Tables.SLAAttainment,
+		"tables.sla_alerts":          c.Tables.SLAAlerts,
+		"tables.sla_calendars":       c.Tables.SLACalendars,
+
+		"tables.sla_calendar_holidays": c.Tables.SLACalendarHolidays,
+
+		"tables.summary_uptime_daily":   c.Tables.SummaryUptimeDaily,
+		"tables.summary_uptime_weekly":  c.Tables.SummaryUptimeWeekly,
+		"tables.summary_uptime_monthly": c.Tables.SummaryUptimeMonthly,
+	} {
+		if !identifierRegexp.MatchString(table) {
+			fail("%s bukan nama tabel yang valid: %q", name, table)
+		}
+	}
+
+	if len(c.Filters.Uptime.StatusIDs) == 0 {
+		fail("filters.uptime.status_ids wajib diisi")
+	}
+	if len(c.Filters.Downtime.StatusIDs) == 0 {
+		fail("filters.downtime.status_ids wajib diisi")
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,167 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/config"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "slauptime.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func load(args ...string) (*config.Config, error) {
+	return config.Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, `
+probe:
+  interval: 10s
+  concurrency: 50
+  timeout: 2s
+filters:
+  uptime:
+    status_ids: [7, 9]
+`)
+	t.Setenv("SLA_PROBE_CONCURRENCY", "60")
+	t.Setenv("SLA_PROBE_TIMEOUT", "3s")
+	t.Setenv("SLA_FILTERS_DOWNTIME_STATUS_IDS", "8, 10")
+
+	cfg, err := load("-config", path, "-probe.timeout", "4s", "-summary.histogram_buckets", "5,50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key       string
+		got, want any
+	}{
+		{"probe.count (default)", cfg.Probe.Count, 1},
+		{"probe.interval (YAML)", cfg.Probe.Interval, 10 * time.Second},
+		{"probe.concurrency (env di atas YAML)", cfg.Probe.Concurrency, 60},
+		{"probe.timeout (flag di atas env)", cfg.Probe.Timeout, 4 * time.Second},
+		{"filters.uptime.status_ids (YAML)", cfg.Filters.Uptime.StatusIDs, []int{7, 9}},
+		{"filters.downtime.status_ids (env)", cfg.Filters.Downtime.StatusIDs, []int{8, 10}},
+		{"summary.histogram_buckets (flag)", cfg.Summary.Buckets, []float64{5, 50}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %v, seharusnya %v", tt.key, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{"key YAML tidak dikenal", "probe:\n  intervall: 10s\n", nil, nil, "gagal membaca file konfigurasi"},
+		{"env tidak bisa dibaca", "", map[string]string{"SLA_PROBE_INTERVAL": "sebentar"}, nil, "environment SLA_PROBE_INTERVAL"},
+		{"flag tidak bisa dibaca", "", nil, []string{"-probe.count", "satu"}, "-probe.count"},
+		{"hasil akhir divalidasi", "probe:\n  mode: raw\n", nil, nil, "probe.mode harus auto"},
+		{"flag memperbaiki YAML", "probe:\n  mode: raw\n", nil, []string{"-probe.mode", "exec"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := load(append([]string{"-config", writeFile(t, tt.yaml)}, tt.args...)...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("error %v, seharusnya berhasil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %v, seharusnya berisi %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// File yang disebut lewat -config wajib ada
+	if _, err := load("-config", filepath.Join(t.TempDir(), "tidak-ada.yaml")); err == nil {
+		t.Error("-config ke file yang tidak ada berhasil, seharusnya error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *config.Config)
+		want   []string // potongan pesan yang harus muncul, kosong = valid
+	}{
+		{"default", func(c *config.Config) {}, nil},
+		{"dsn tanpa parseTime", func(c *config.Config) { c.MySQL.DSN = "user:pass@tcp(localhost:3306)/sla" }, []string{"parseTime=true"}},
+		{"dsn kosong", func(c *config.Config) { c.MySQL.DSN = " " }, []string{"mysql.dsn wajib diisi"}},
+		{"packet_interval hanya wajib jika count > 1", func(c *config.Config) { c.Probe.PacketInterval = 0 }, nil},
+		{"packet_interval nol dengan count 3", func(c *config.Config) { c.Probe.Count, c.Probe.PacketInterval = 3, 0 }, []string{"probe.packet_interval"}},
+		{"max_loss 100", func(c *config.Config) { c.Probe.MaxLoss = 100 }, []string{"probe.max_loss"}},
+		{"batch_size melewati batas placeholder", func(c *config.Config) { c.Writer.BatchSize = config.MaxBatchSize + 1 }, []string{"writer.batch_size"}},
+		{"long_window tidak lebih dari short_window", func(c *config.Config) { c.Alerts.LongWindow = c.Alerts.ShortWindow }, []string{"alerts.short_window"}},
+		{"webhook bukan http", func(c *config.Config) { c.Alerts.WebhookURL = "ftp://example.com/hook" }, []string{"alerts.webhook_url"}},
+		{"timezone tidak dikenal", func(c *config.Config) { c.Report.Timezone = "Asia/Bandung" }, []string{"report.timezone"}},
+		{"ping_results_timezone host", func(c *config.Config) { c.Migrate.PingResultsTimezone = config.HostTimezone }, nil},
+		{"bucket tidak urut", func(c *config.Config) { c.Summary.Buckets = []float64{10, 5} }, []string{"summary.histogram_buckets"}},
+		{"tanpa bucket", func(c *config.Config) { c.Summary.Buckets = nil }, nil},
+		{"missing_data tidak dikenal", func(c *config.Config) { c.Summary.MissingData = "unknown" }, []string{"summary.missing_data"}},
+		{"nama tabel berbahaya", func(c *config.Config) { c.Tables.SLAAlerts = "sla_alerts; DROP TABLE x" }, []string{"tables.sla_alerts"}},
+		{"semua kesalahan dikumpulkan", func(c *config.Config) {
+			c.Probe.Interval = 0
+			c.Probe.Sink = "kafka"
+			c.Filters.Uptime.StatusIDs = nil
+		}, []string{"probe.interval", "probe.sink", "filters.uptime.status_ids"}},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		tt.change(&cfg)
+		err := cfg.Validate()
+		if len(tt.want) == 0 {
+			if err != nil {
+				t.Errorf("%s: %v, seharusnya valid", tt.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: valid, seharusnya error %v", tt.name, tt.want)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: error %q, seharusnya menyebut %s", tt.name, err, want)
+			}
+		}
+	}
+}
+
+func TestSampleGap(t *testing.T) {
+	tests := []struct {
+		interval, maxGap, want time.Duration
+	}{
+		{5 * time.Second, 0, 15 * time.Second},
+		{time.Minute, 0, 3 * time.Minute},
+		{5 * time.Second, 2 * time.Minute, 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.Probe.Interval, cfg.Summary.MaxSampleGap = tt.interval, tt.maxGap
+		if got := cfg.SampleGap(); got != tt.want {
+			t.Errorf("SampleGap(interval %s, max_sample_gap %s) = %s, seharusnya %s", tt.interval, tt.maxGap, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File konfigurasi yang dicari jika -config dan SLA_CONFIG tidak diisi
+const DefaultPath = "slauptime.yaml"
+
+// Prefix environment variable, contoh: SLA_MYSQL_DSN, SLA_PROBE_INTERVAL
+const EnvPrefix = "SLA_"
+
+// Load membaca konfigurasi dari file, environment variable dan flag lalu memvalidasinya.
+// Setiap nilai bisa di-override dengan flag bernama sesuai key YAML,
+// contoh -mysql.dsn, -probe.interval 10s, -filters.uptime.status_ids 7,9.
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+
+	path := flags.String("config", "", "file konfigurasi YAML (default "+DefaultPath+" atau $"+EnvPrefix+"CONFIG)")
+	fieldList := fields(reflect.ValueOf(&cfg).Elem(), "")
+	overrides := make(map[string]*string)
+	for _, f := range fieldList {
+		raw := new(string)
+		overrides[f.key] = raw
+		flags.Var(stringValue{raw, f.current()}, f.key, "override "+f.key+" (env "+f.env()+")")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := loadFile(&cfg, *path); err != nil {
+		return nil, err
+	}
+
+	// Environment variable lalu flag, flag menang
+	for _, f := range fieldList {
+		if raw, ok := os.LookupEnv(f.env()); ok {
+			if err := f.set(raw); err != nil {
+				return nil, fmt.Errorf("environment %s: %w", f.env(), err)
+			}
+		}
+	}
+	var flagErr error
+	flags.Visit(func(fl *flag.Flag) {
+		raw, ok := overrides[fl.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+		for _, f := range fieldList {
+			if f.key == fl.Name {
+				if err := f.set(*raw); err != nil {
+					flagErr = fmt.Errorf("flag -%s: %w", fl.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("konfigurasi tidak valid:\n%w", err)
+	}
+	return &cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv(EnvPrefix + "CONFIG")
+		explicit = path != ""
+	}
+	if !explicit {
+		path = DefaultPath
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		// File default boleh tidak ada, cukup pakai nilai default + env + flag
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("gagal membuka file konfigurasi: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("gagal membaca file konfigurasi %s: %w", path, err)
+	}
+	return nil
+}
+
+// Satu nilai konfigurasi beserta key YAML lengkapnya, contoh "probe.interval"
+type field struct {
+	key   string
+	value reflect.Value
+}
+
+func (f field) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))
+}
+
+func fields(v reflect.Value, prefix string) []field {
+	var out []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + tag
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			out = append(out, fields(fv, key+".")...)
+			continue
+		}
+		out = append(out, field{key: key, value: fv})
+	}
+	return out
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (f field) current() string {
+	switch {
+	case f.value.Type() == durationType:
+		return time.Duration(f.value.Int()).String()
+	case f.value.Kind() == reflect.Slice:
+		parts := make([]string, f.value.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprint(f.value.Index(i).Interface())
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(f.value.Interface())
+	}
+}
+
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(raw)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		f.value.SetFloat(n)
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	case f.value.Kind() == reflect.Slice && f.value.Type().Elem().Kind() == reflect.Int:
+		var ids []int
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, n)
+		}
+		f.value.Set(reflect.ValueOf(ids))
+	case f.value.Kind() == reflect.Slice && f.value.Type().Elem().Kind() == reflect.Float64:
+		var values []float64
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			n, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return err
+			}
+			values = append(values, n)
+		}
+		f.value.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("tipe %s belum didukung", f.value.Type())
+	}
+	return nil
+}
+
+// flag.Value yang hanya menyimpan string mentah, konversi dilakukan
+// setelah file konfigurasi dibaca supaya urutan prioritas tetap benar
+type stringValue struct {
+	raw *string
+	def string
+}
+
+func (s stringValue) String() string {
+	if s.raw == nil {
+		return ""
+	}
+	return s.def
+}
+
+func (s stringValue) Set(v string) error {
+	*s.raw = v
+	return nil
+}
@@ -0,0 +1,372 @@
+// Package incident menyusun insiden down per target (mulai, selesai, lama,
+// klasifikasi) dari transisi status di ping_results.
+//
+// Insiden dimulai di sampel gagal pertama dan selesai di sampel sukses
+// berikutnya, jadi insiden yang melewati batas jam tetap utuh. Jika
+// klasifikasinya berubah di tengah insiden (misalnya masuk jendela
+// maintenance), insiden ditutup dan yang baru dibuka dengan klasifikasi baru.
+// Builder memproses ping_results berdasarkan id, di transaksi yang sama dengan
+// perubahan insidennya. Karena dua penulis (probe dan upload raw) bisa commit
+// bersamaan, id yang lebih kecil bisa baru terlihat setelah id yang lebih
+// besar dibaca. Jadi pembacaan dimulai dari settled_id di incident_state, yaitu
+// posisi yang sudah lebih lama dari incidents.settle_time, dan sampel yang
+// sudah diproses dilewati berdasarkan waktu sampel terakhir per target.
+// Sampel yang lebih tua dari sampel terakhir target itu yang sudah diproses
+// juga dilewati, supaya insiden lama tidak dibuka ulang.
+package incident
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/maintenance"
+	"sla_uptime/internal/rules"
+)
+
+// Nama baris di incident_state untuk posisi ping_results
+const stateName = "ping_results"
+
+// Incident adalah satu baris tabel incidents
+type Incident struct {
+	ID           int64
+	IPID         int
+	StartedAt    time.Time
+	EndedAt      sql.NullTime // tidak Valid = masih berlangsung
+	LastSampleAt time.Time    // sampel gagal terakhir
+	Class        rules.Class
+	StatusID     sql.NullInt64
+	ReasonID     sql.NullInt64
+	FailCount    int
+
+	dirty bool
+}
+
+// Open mengembalikan true jika insiden belum selesai
+func (i *Incident) Open() bool {
+	return !i.EndedAt.Valid
+}
+
+// Duration mengembalikan lama insiden, untuk insiden terbuka sampai sampel
+// gagal terakhir
+func (i *Incident) Duration() time.Duration {
+	if i.EndedAt.Valid {
+		return i.EndedAt.Time.Sub(i.StartedAt)
+	}
+	return i.LastSampleAt.Sub(i.StartedAt)
+}
+
+// Result adalah hasil satu kali Build
+type Result struct {
+	Processed int   // sampel ping_results baru yang diproses
+	Skipped   int   // sampel yang dibaca ulang atau terlambat, dilewati
+	Opened    int   // insiden baru
+	Closed    int   // insiden yang selesai
+	LastID    int64 // id ping_results terakhir yang sudah diproses
+	SettledID int64 // id sampai sini tidak dibaca ulang lagi
+}
+
+// Build memproses semua ping_results setelah settled_id, batchSize sampel per
+// transaksi. loc adalah zona waktu cron jendela maintenance. settle adalah
+// batas lama transaksi penulis ping_results sampai commit; 0 berarti tidak
+// ada pembacaan ulang.
+func Build(db *sql.DB, tables config.TablesConfig, set *rules.Set, loc *time.Location, batchSize int, settle time.Duration) (Result, error) {
+	var result Result
+	r := &run{started: time.Now(), cursor: -1, settle: settle}
+	for {
+		n, err := buildBatch(db, tables, set, loc, batchSize, r, &result)
+		if err != nil {
+			return result, err
+		}
+		if n < batchSize {
+			return result, nil
+		}
+	}
+}
+
+// Posisi pembacaan satu kali Build
+type run struct {
+	started time.Time     // sebelum pembacaan pertama
+	cursor  int64         // id terakhir yang dibaca, -1 = belum membaca
+	settle  time.Duration // incidents.settle_time
+}
+
+// Baris incident_state
+type state struct {
+	lastID, settledID, checkpointID int64
+	checkpointAt                    sql.NullTime
+}
+
+// Memajukan settled_id setelah pembacaan. Semua id sampai checkpoint_id sudah
+// dialokasikan sebelum checkpoint_at, jadi setelah settle lewat semuanya pasti
+// sudah commit. settled_id baru boleh maju jika pembacaan run ini dimulai
+// setelah itu dan sudah mencapai checkpoint_id.
+func (st *state) advance(r *run, now time.Time) {
+	if r.settle <= 0 {
+		st.settledID, st.checkpointID = st.lastID, st.lastID
+		st.checkpointAt = sql.NullTime{Time: now, Valid: true}
+		return
+	}
+	if st.checkpointAt.Valid {
+		if r.started.Sub(st.checkpointAt.Time) < r.settle || r.cursor < st.checkpointID {
+			return
+		}
+		st.settledID = st.checkpointID
+	}
+	st.checkpointID = st.lastID
+	st.checkpointAt = sql.NullTime{Time: now, Valid: true}
+}
+
+type sample struct {
+	id       int64
+	ipID     int
+	at       time.Time
+	up       bool
+	statusID sql.NullInt64
+	reasonID sql.NullInt64
+}
+
+// Memproses satu batch dan mengembalikan jumlah sampel yang dibaca
+func buildBatch(db *sql.DB, tables config.TablesConfig, set *rules.Set, loc *time.Location, batchSize int, r *run, result *Result) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Baris state dikunci supaya dua builder tidak jalan bersamaan
+	_, err = tx.Exec("INSERT INTO "+tables.IncidentState+" (name, last_id, updated_at) VALUES (?, 0, ?)"+
+		" ON DUPLICATE KEY UPDATE name = name", stateName, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("gagal menyiapkan %s: %w", tables.IncidentState, err)
+	}
+	var st state
+	err = tx.QueryRow("SELECT last_id, settled_id, checkpoint_id, checkpoint_at FROM "+tables.IncidentState+
+		" WHERE name = ? FOR UPDATE", stateName).Scan(&st.lastID, &st.settledID, &st.checkpointID, &st.checkpointAt)
+	if err != nil {
+		return 0, fmt.Errorf("gagal membaca posisi %s: %w", tables.IncidentState, err)
+	}
+	if r.cursor < st.settledID {
+		r.cursor = st.settledID
+	}
+
+	samples, err := readAfter(tx, tables.PingResults, r.cursor, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range samples {
+		if s.id > r.cursor {
+			r.cursor = s.id
+		}
+		if s.id > st.lastID {
+			st.lastID = s.id
+		}
+	}
+
+	now := time.Now()
+	if len(samples) > 0 {
+		if err := apply(db, tx, tables, set, loc, samples, now, result); err != nil {
+			return 0, err
+		}
+	}
+
+	st.advance(r, now)
+	_, err = tx.Exec("UPDATE "+tables.IncidentState+" SET last_id = ?, settled_id = ?, checkpoint_id = ?, checkpoint_at = ?, updated_at = ?"+
+		" WHERE name = ?", st.lastID, st.settledID, st.checkpointID, st.checkpointAt, now, stateName)
+	if err != nil {
+		return 0, fmt.Errorf("gagal menyimpan posisi %s: %w", tables.IncidentState, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	result.LastID = st.lastID
+	result.SettledID = st.settledID
+	return len(samples), nil
+}
+
+// Menerapkan sampel ke insiden dan menyimpan perubahannya di tx
+func apply(db *sql.DB, tx *sql.Tx, tables config.TablesConfig, set *rules.Set, loc *time.Location, samples []sample, now time.Time, result *Result) error {
+	first, last := samples[0].at, samples[0].at
+	for _, s := range samples {
+		if s.at.Before(first) {
+			first = s.at
+		}
+		if s.at.After(last) {
+			last = s.at
+		}
+	}
+	schedule, err := maintenance.Load(db, tables, loc, first, last.Add(time.Second))
+	if err != nil {
+		return err
+	}
+
+	open, err := loadOpen(tx, tables.Incidents)
+	if err != nil {
+		return err
+	}
+	seen, err := loadSeen(tx, tables.IncidentTargets)
+	if err != nil {
+		return err
+	}
+
+	classify := func(s sample) rules.Class {
+		return set.ClassifyAt(schedule, s.ipID, s.at, s.statusID, s.reasonID)
+	}
+	changed, updated := transition(open, seen, samples, classify, result)
+
+	for _, inc := range changed {
+		if err := save(tx, tables.Incidents, inc, now); err != nil {
+			return err
+		}
+	}
+
+	return saveSeen(tx, tables.IncidentTargets, updated, now)
+}
+
+func readAfter(tx *sql.Tx, table string, lastID int64, limit int) ([]sample, error) {
+	rows, err := tx.Query(`
+        SELECT id, ip_id, timestamp, status, status_id, reason_id
+        FROM `+table+`
+        WHERE id > ?
+        ORDER BY id
+        LIMIT ?
+    `, lastID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var samples []sample
+	for rows.Next() {
+		var s sample
+		var status string
+		if err := rows.Scan(&s.id, &s.ipID, &s.at, &status, &s.statusID, &s.reasonID); err != nil {
+			return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		s.up = status == "1"
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+const selectColumns = "id, ip_id, started_at, ended_at, last_sample_at, classification, status_id, reason_id, fail_count"
+
+func scanIncident(rows *sql.Rows) (*Incident, error) {
+	var inc Incident
+	var class string
+	err := rows.Scan(&inc.ID, &inc.IPID, &inc.StartedAt, &inc.EndedAt, &inc.LastSampleAt,
+		&class, &inc.StatusID, &inc.ReasonID, &inc.FailCount)
+	inc.Class = rules.Class(class)
+	return &inc, err
+}
+
+// Insiden yang masih berlangsung, per ip_id
+func loadOpen(tx *sql.Tx, table string) (map[int]*Incident, error) {
+	rows, err := tx.Query("SELECT " + selectColumns + " FROM " + table + " WHERE ended_at IS NULL")
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca insiden terbuka dari %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	open := make(map[int]*Incident)
+	for rows.Next() {
+		inc, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca insiden terbuka dari %s: %w", table, err)
+		}
+		open[inc.IPID] = inc
+	}
+	return open, rows.Err()
+}
+
+// Waktu sampel terakhir yang sudah diproses per ip_id
+func loadSeen(tx *sql.Tx, table string) (map[int]time.Time, error) {
+	rows, err := tx.Query("SELECT ip_id, last_sample_at FROM " + table)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	seen := make(map[int]time.Time)
+	for rows.Next() {
+		var ipID int
+		var at time.Time
+		if err := rows.Scan(&ipID, &at); err != nil {
+			return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		seen[ipID] = at
+	}
+	return seen, rows.Err()
+}
+
+func saveSeen(tx *sql.Tx, table string, seen map[int]time.Time, now time.Time) error {
+	for ipID, at := range seen {
+		_, err := tx.Exec("INSERT INTO "+table+" (ip_id, last_sample_at, updated_at) VALUES (?, ?, ?)"+
+			" ON DUPLICATE KEY UPDATE last_sample_at = VALUES(last_sample_at), updated_at = VALUES(updated_at)",
+			ipID, at, now)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan %s ip_id %d: %w", table, ipID, err)
+		}
+	}
+	return nil
+}
+
+func save(tx *sql.Tx, table string, inc *Incident, now time.Time) error {
+	seconds := int(inc.Duration().Seconds())
+	if inc.ID == 0 {
+		res, err := tx.Exec(`
+            INSERT INTO `+table+` (ip_id, started_at, ended_at, last_sample_at, duration_seconds,
+                                   classification, status_id, reason_id, fail_count, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        `, inc.IPID, inc.StartedAt, inc.EndedAt, inc.LastSampleAt, seconds,
+			string(inc.Class), inc.StatusID, inc.ReasonID, inc.FailCount, now)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan insiden ip_id %d ke %s: %w", inc.IPID, table, err)
+		}
+		inc.ID, _ = res.LastInsertId()
+		return nil
+	}
+
+	_, err := tx.Exec(`
+        UPDATE `+table+`
+        SET ended_at = ?, last_sample_at = ?, duration_seconds = ?, fail_count = ?, updated_at = ?
+        WHERE id = ?
+    `, inc.EndedAt, inc.LastSampleAt, seconds, inc.FailCount, now, inc.ID)
+	if err != nil {
+		return fmt.Errorf("gagal memperbarui insiden %d di %s: %w", inc.ID, table, err)
+	}
+	return nil
+}
+
+// List mengembalikan insiden yang beririsan dengan [from, to), urut waktu
+// mulai. ipID 0 berarti semua target.
+func List(db *sql.DB, table string, from, to time.Time, ipID int, openOnly bool) ([]*Incident, error) {
+	where := []string{"started_at < ?", "(ended_at IS NULL OR ended_at > ?)"}
+	args := []interface{}{to, from}
+	if ipID != 0 {
+		where = append(where, "ip_id = ?")
+		args = append(args, ipID)
+	}
+	if openOnly {
+		where = append(where, "ended_at IS NULL")
+	}
+
+	rows, err := db.Query("SELECT "+selectColumns+" FROM "+table+
+		" WHERE "+strings.Join(where, " AND ")+" ORDER BY started_at, ip_id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var incidents []*Incident
+	for rows.Next() {
+		inc, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		incidents = append(incidents, inc)
+	}
+	return incidents, rows.Err()
+}
@@ -0,0 +1,132 @@
+package incident_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/incident"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/testdb"
+)
+
+var t0 = time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+
+func insertSample(t *testing.T, db *sql.DB, tables config.TablesConfig, id int64, ipID int, at time.Time, up bool) {
+	t.Helper()
+	status := "0"
+	if up {
+		status = "1"
+	}
+	_, err := db.Exec("INSERT INTO "+tables.PingResults+" (id, ip_id, timestamp, status) VALUES (?, ?, ?, ?)", id, ipID, at, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func slaRules(t *testing.T) *rules.Set {
+	t.Helper()
+	set, err := rules.New([]rules.Rule{{Class: rules.SLA}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func build(t *testing.T, db *sql.DB, cfg *config.Config, settle time.Duration) incident.Result {
+	t.Helper()
+	result, err := incident.Build(db, cfg.Tables, slaRules(t), time.UTC, 100, settle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func openIncidents(t *testing.T, db *sql.DB, cfg *config.Config) map[int]*incident.Incident {
+	t.Helper()
+	list, err := incident.List(db, cfg.Tables.Incidents, t0.Add(-time.Hour), t0.Add(time.Hour), 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byIP := make(map[int]*incident.Incident)
+	for _, inc := range list {
+		byIP[inc.IPID] = inc
+	}
+	return byIP
+}
+
+// id 3 baru commit setelah id 4 diproses (dua penulis bersamaan). Transisi di
+// id 3 tidak boleh hilang, dan sampel yang dibaca ulang tidak dihitung dua kali.
+func TestBuildOutOfOrderIDs(t *testing.T) {
+	db, cfg := testdb.MySQL(t)
+	tables := cfg.Tables
+
+	insertSample(t, db, tables, 1, 1, t0, true)
+	insertSample(t, db, tables, 2, 1, t0.Add(time.Minute), true)
+	insertSample(t, db, tables, 4, 2, t0.Add(time.Minute), false)
+
+	first := build(t, db, cfg, time.Hour)
+	if first.Processed != 3 || first.Opened != 1 || first.LastID != 4 {
+		t.Fatalf("build pertama: %+v", first)
+	}
+
+	insertSample(t, db, tables, 3, 1, t0.Add(2*time.Minute), false)
+	second := build(t, db, cfg, time.Hour)
+	if second.Processed != 1 || second.Skipped != 3 || second.Opened != 1 {
+		t.Errorf("build kedua: %+v, seharusnya 1 diproses, 3 dilewati, 1 insiden baru", second)
+	}
+	if second.SettledID != 0 {
+		t.Errorf("settled_id %d, seharusnya belum maju sebelum settle_time lewat", second.SettledID)
+	}
+
+	open := openIncidents(t, db, cfg)
+	if inc := open[1]; inc == nil || !inc.StartedAt.Equal(t0.Add(2*time.Minute)) {
+		t.Errorf("insiden ip_id 1 dari id 3 tidak ada: %+v", inc)
+	}
+	if inc := open[2]; inc == nil || inc.FailCount != 1 {
+		t.Errorf("insiden ip_id 2 seharusnya 1 sampel gagal (tidak dihitung ulang): %+v", inc)
+	}
+
+	// Setelah settle_time lewat dan run sudah membaca sampai checkpoint,
+	// settled_id maju dan id lama tidak dibaca ulang lagi
+	if _, err := db.Exec("UPDATE "+tables.IncidentState+" SET checkpoint_at = ?", time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	third := build(t, db, cfg, time.Hour)
+	if third.SettledID != 4 {
+		t.Errorf("settled_id %d, seharusnya 4", third.SettledID)
+	}
+	fourth := build(t, db, cfg, time.Hour)
+	if fourth.Processed != 0 || fourth.Skipped != 0 {
+		t.Errorf("build setelah settle: %+v, seharusnya tidak ada yang dibaca ulang", fourth)
+	}
+}
+
+// Waktu sampel terakhir per target tersimpan di incident_targets, jadi sampel
+// terlambat juga dilewati di build berikutnya (aturannya di TestTransition)
+func TestBuildLateSample(t *testing.T) {
+	db, cfg := testdb.MySQL(t)
+	tables := cfg.Tables
+
+	insertSample(t, db, tables, 1, 1, t0, false)
+	insertSample(t, db, tables, 2, 1, t0.Add(2*time.Minute), true)
+	if r := build(t, db, cfg, 0); r.Opened != 1 || r.Closed != 1 {
+		t.Fatalf("build pertama: %+v", r)
+	}
+
+	insertSample(t, db, tables, 3, 1, t0.Add(time.Minute), false) // terlambat
+	insertSample(t, db, tables, 4, 1, t0.Add(3*time.Minute), false)
+	r := build(t, db, cfg, 0)
+	if r.Processed != 1 || r.Skipped != 1 || r.Opened != 1 || r.Closed != 0 {
+		t.Errorf("build kedua: %+v, seharusnya sampel terlambat dilewati", r)
+	}
+	if r.SettledID != r.LastID {
+		t.Errorf("tanpa settle_time settled_id %d seharusnya sama dengan last_id %d", r.SettledID, r.LastID)
+	}
+
+	open := openIncidents(t, db, cfg)
+	if inc := open[1]; inc == nil || !inc.StartedAt.Equal(t0.Add(3*time.Minute)) || inc.FailCount != 1 {
+		t.Errorf("insiden terbuka ip_id 1: %+v, seharusnya mulai dari id 4", inc)
+	}
+}
@@ -0,0 +1,73 @@
+package incident
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/rules"
+)
+
+// Menerapkan sampel satu batch ke insiden terbuka (per ip_id, diubah di
+// tempat). seen adalah waktu sampel terakhir yang sudah diproses per ip_id:
+// sampel yang tidak lebih baru dari itu dilewati, baik karena dibaca ulang di
+// jendela settle maupun karena datang terlambat dengan timestamp yang lebih
+// tua. Sampel terlambat sengaja dibuang, bukan disisipkan, supaya insiden
+// yang sudah tersimpan tidak ditulis ulang. Mengembalikan insiden yang
+// berubah (sesuai urutan) dan waktu sampel terakhir per ip_id yang diproses.
+func transition(open map[int]*Incident, seen map[int]time.Time, samples []sample, classify func(sample) rules.Class, result *Result) ([]*Incident, map[int]time.Time) {
+	// Per target urut waktu; id menentukan batch, bukan urutan transisi
+	sort.SliceStable(samples, func(i, j int) bool {
+		if samples[i].ipID != samples[j].ipID {
+			return samples[i].ipID < samples[j].ipID
+		}
+		return samples[i].at.Before(samples[j].at)
+	})
+
+	var changed []*Incident
+	touch := func(inc *Incident) {
+		if !inc.dirty {
+			inc.dirty = true
+			changed = append(changed, inc)
+		}
+	}
+	updated := make(map[int]time.Time)
+	for _, s := range samples {
+		if last, ok := seen[s.ipID]; ok && !s.at.After(last) {
+			result.Skipped++ // sudah diproses (dibaca ulang) atau terlambat
+			continue
+		}
+		result.Processed++
+		seen[s.ipID] = s.at
+		updated[s.ipID] = s.at
+
+		cur := open[s.ipID]
+		class := classify(s)
+
+		if cur != nil && (s.up || class != cur.Class) {
+			cur.EndedAt = sql.NullTime{Time: s.at, Valid: true}
+			touch(cur)
+			delete(open, s.ipID)
+			result.Closed++
+			cur = nil
+		}
+		if s.up {
+			continue
+		}
+		if cur == nil {
+			cur = &Incident{
+				IPID:      s.ipID,
+				StartedAt: s.at,
+				Class:     class,
+				StatusID:  s.statusID,
+				ReasonID:  s.reasonID,
+			}
+			open[s.ipID] = cur
+			result.Opened++
+		}
+		cur.LastSampleAt = s.at
+		cur.FailCount++
+		touch(cur)
+	}
+	return changed, updated
+}
@@ -0,0 +1,122 @@
+package incident
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/rules"
+)
+
+func TestTransition(t *testing.T) {
+	t0 := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	minute := func(m int) time.Time { return t0.Add(time.Duration(m) * time.Minute) }
+	up := func(ipID, m int) sample { return sample{ipID: ipID, at: minute(m), up: true} }
+	down := func(ipID, m int) sample { return sample{ipID: ipID, at: minute(m)} }
+	// Menit 30-39 masuk jendela maintenance
+	classify := func(s sample) rules.Class {
+		if !s.at.Before(minute(30)) && s.at.Before(minute(40)) {
+			return rules.Scheduled
+		}
+		return rules.SLA
+	}
+	// Ringkasan insiden: ip_id, mulai-selesai (menit, - = terbuka), sampel gagal, klasifikasi
+	describe := func(incs []*Incident) string {
+		var out []string
+		for _, inc := range incs {
+			end := "-"
+			if inc.EndedAt.Valid {
+				end = fmt.Sprint(inc.EndedAt.Time.Sub(t0).Minutes())
+			}
+			out = append(out, fmt.Sprintf("%d:%v-%s/%d/%s", inc.IPID, inc.StartedAt.Sub(t0).Minutes(), end, inc.FailCount, inc.Class))
+		}
+		return strings.Join(out, " ")
+	}
+
+	tests := []struct {
+		name    string
+		open    []*Incident
+		seen    map[int]time.Time
+		samples []sample
+		changed string
+		open2   int // insiden terbuka setelahnya
+		result  Result
+	}{
+		{
+			name:    "down lalu pulih",
+			samples: []sample{up(1, 0), down(1, 1), down(1, 2), up(1, 3)},
+			changed: "1:1-3/2/sla",
+			result:  Result{Processed: 4, Opened: 1, Closed: 1},
+		},
+		{
+			name:    "urut waktu, bukan urut id",
+			samples: []sample{down(1, 2), up(1, 3), up(1, 1)},
+			changed: "1:2-3/1/sla",
+			result:  Result{Processed: 3, Opened: 1, Closed: 1},
+		},
+		{
+			name:    "target terpisah",
+			samples: []sample{down(2, 1), down(1, 1), up(2, 2)},
+			changed: "1:1--/1/sla 2:1-2/1/sla",
+			open2:   1,
+			result:  Result{Processed: 3, Opened: 2, Closed: 1},
+		},
+		{
+			name:    "klasifikasi berubah di tengah insiden",
+			samples: []sample{down(1, 29), down(1, 30), down(1, 40)},
+			changed: "1:29-30/1/sla 1:30-40/1/scheduled 1:40--/1/sla",
+			open2:   1,
+			result:  Result{Processed: 3, Opened: 3, Closed: 2},
+		},
+		{
+			name:    "insiden terbuka dari batch sebelumnya",
+			open:    []*Incident{{ID: 7, IPID: 1, StartedAt: minute(1), LastSampleAt: minute(2), Class: rules.SLA, FailCount: 2}},
+			seen:    map[int]time.Time{1: minute(2)},
+			samples: []sample{down(1, 3), up(1, 4)},
+			changed: "1:1-4/3/sla",
+			result:  Result{Processed: 2, Closed: 1},
+		},
+		{
+			name:    "dibaca ulang di jendela settle",
+			open:    []*Incident{{ID: 7, IPID: 1, StartedAt: minute(1), LastSampleAt: minute(2), Class: rules.SLA, FailCount: 2}},
+			seen:    map[int]time.Time{1: minute(2)},
+			samples: []sample{down(1, 1), down(1, 2)},
+			open2:   1,
+			result:  Result{Skipped: 2},
+		},
+		{
+			// Insiden menit 1-3 sudah tersimpan, sampel menit 2 yang baru
+			// commit tidak membuka atau menutupnya lagi
+			name:    "timestamp terlambat dibuang",
+			seen:    map[int]time.Time{1: minute(3)},
+			samples: []sample{down(1, 2), up(1, 2), down(1, 4)},
+			changed: "1:4--/1/sla",
+			open2:   1,
+			result:  Result{Processed: 1, Skipped: 2, Opened: 1},
+		},
+	}
+	for _, tt := range tests {
+		open := make(map[int]*Incident)
+		for _, inc := range tt.open {
+			open[inc.IPID] = inc
+		}
+		seen := make(map[int]time.Time)
+		for ipID, at := range tt.seen {
+			seen[ipID] = at
+		}
+		var result Result
+		changed, updated := transition(open, seen, tt.samples, classify, &result)
+		if got := describe(changed); got != tt.changed {
+			t.Errorf("%s: insiden berubah %q, seharusnya %q", tt.name, got, tt.changed)
+		}
+		if len(open) != tt.open2 || result != tt.result {
+			t.Errorf("%s: %d terbuka, %+v, seharusnya %d terbuka, %+v", tt.name, len(open), result, tt.open2, tt.result)
+		}
+		for ipID, at := range updated {
+			if !seen[ipID].Equal(at) {
+				t.Errorf("%s: seen ip_id %d %s, seharusnya %s", tt.name, ipID, seen[ipID], at)
+			}
+		}
+	}
+}
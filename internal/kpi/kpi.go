@@ -0,0 +1,238 @@
+// Package kpi menghitung MTTR, MTBF, jumlah insiden dan availability per
+// periode, per ip_id dan per grup, dari insiden berklasifikasi sla di tabel
+// incidents (yang disusun dari transisi status di ping_results).
+package kpi
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/incident"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/summary"
+	"sla_uptime/internal/target"
+)
+
+// KPI untuk satu target (IPID diisi) atau satu grup (Group diisi) dalam satu periode
+type KPI struct {
+	IPID     int
+	Group    string
+	Targets  int           // jumlah target yang dihitung
+	Observed time.Duration // lama periode yang sudah lewat x jumlah target
+
+	Incidents int           // insiden yang mulai di periode ini
+	Open      int           // dari Incidents, yang belum selesai
+	Downtime  time.Duration // lama insiden di dalam periode, termasuk yang mulai sebelumnya
+	MTTR      sql.NullFloat64
+	MTBF      sql.NullFloat64
+
+	Availability float64 // persen
+
+	repaired     int
+	repairedTime time.Duration
+}
+
+func (k *KPI) finish() {
+	if k.repaired > 0 {
+		k.MTTR = sql.NullFloat64{Float64: k.repairedTime.Seconds() / float64(k.repaired), Valid: true}
+	}
+	up := k.Observed - k.Downtime
+	if k.Incidents > 0 {
+		k.MTBF = sql.NullFloat64{Float64: up.Seconds() / float64(k.Incidents), Valid: true}
+	}
+	if k.Observed > 0 {
+		k.Availability = up.Seconds() / k.Observed.Seconds() * 100
+	}
+}
+
+func (k *KPI) add(other *KPI) {
+	k.Targets += other.Targets
+	k.Observed += other.Observed
+	k.Incidents += other.Incidents
+	k.Open += other.Open
+	k.Downtime += other.Downtime
+	k.repaired += other.repaired
+	k.repairedTime += other.repairedTime
+}
+
+// Compute menghitung KPI periode yang dimulai di start, dihitung sampai now
+// jika periodenya belum selesai. Target yang dihitung adalah yang punya baris
+// summary_uptime atau insiden sla di periode itu. Hasilnya baris per ip_id
+// lalu baris per grup (group_name di ip_monitor), lihat Aggregate.
+func Compute(db *sql.DB, tables config.TablesConfig, period summary.Period, start, now time.Time) ([]KPI, error) {
+	end := period.Next(start)
+	observedEnd := end
+	if now.Before(observedEnd) {
+		observedEnd = now
+	}
+	if !observedEnd.After(start) {
+		return nil, nil
+	}
+
+	targets, err := targetsIn(db, tables, start, end)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := incident.List(db, tables.Incidents, start, observedEnd, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := target.Groups(db, tables.IPMonitor)
+	if err != nil {
+		return nil, err
+	}
+	return Aggregate(targets, incidents, groups, start, observedEnd), nil
+}
+
+// Aggregate menghitung KPI [start, observedEnd) untuk targets ditambah target
+// yang punya insiden sla di rentang itu. Insiden di luar rentang dan yang
+// bukan sla diabaikan. Hasilnya urut ip_id, lalu satu baris per grup urut
+// nama untuk target yang ada di groups.
+//
+// MTTR = rata-rata lama insiden yang mulai dan sudah selesai, MTBF = waktu up
+// dibagi jumlah insiden, availability = waktu up / waktu periode. Waktu
+// maintenance dan pekerjaan terjadwal tidak dihitung sebagai down.
+func Aggregate(targets map[int]bool, incidents []*incident.Incident, groups map[int]string, start, observedEnd time.Time) []KPI {
+	if !observedEnd.After(start) {
+		return nil
+	}
+	observed := observedEnd.Sub(start)
+
+	byIP := make(map[int]*KPI, len(targets))
+	get := func(ipID int) *KPI {
+		k, ok := byIP[ipID]
+		if !ok {
+			k = &KPI{IPID: ipID, Targets: 1, Observed: observed}
+			byIP[ipID] = k
+		}
+		return k
+	}
+	for ipID := range targets {
+		get(ipID)
+	}
+
+	for _, inc := range incidents {
+		if inc.Class != rules.SLA || !inc.StartedAt.Before(observedEnd) || (!inc.Open() && !inc.EndedAt.Time.After(start)) {
+			continue
+		}
+		k := get(inc.IPID)
+
+		// Bagian insiden di dalam periode
+		from, to := inc.StartedAt, inc.StartedAt.Add(inc.Duration())
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(observedEnd) {
+			to = observedEnd
+		}
+		if to.After(from) {
+			k.Downtime += to.Sub(from)
+		}
+
+		if inc.StartedAt.Before(start) {
+			continue
+		}
+		k.Incidents++
+		if inc.Open() {
+			k.Open++
+		} else {
+			k.repaired++
+			k.repairedTime += inc.Duration()
+		}
+	}
+
+	result := make([]KPI, 0, len(byIP))
+	byGroup := make(map[string]*KPI)
+	for ipID, k := range byIP {
+		if group, ok := groups[ipID]; ok {
+			g, ok := byGroup[group]
+			if !ok {
+				g = &KPI{Group: group}
+				byGroup[group] = g
+			}
+			g.add(k)
+		}
+		k.finish()
+		result = append(result, *k)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].IPID < result[j].IPID })
+
+	names := make([]string, 0, len(byGroup))
+	for name := range byGroup {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g := byGroup[name]
+		g.finish()
+		result = append(result, *g)
+	}
+	return result
+}
+
+// Target yang punya ringkasan uptime di [start, end)
+func targetsIn(db *sql.DB, tables config.TablesConfig, start, end time.Time) (map[int]bool, error) {
+	rows, err := db.Query("SELECT DISTINCT ip_id FROM "+tables.SummaryUptime+" WHERE timestamp >= ? AND timestamp < ?", start, end)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca target dari %s: %w", tables.SummaryUptime, err)
+	}
+	defer rows.Close()
+
+	targets := make(map[int]bool)
+	for rows.Next() {
+		var ipID int
+		if err := rows.Scan(&ipID); err != nil {
+			return nil, fmt.Errorf("gagal membaca target dari %s: %w", tables.SummaryUptime, err)
+		}
+		targets[ipID] = true
+	}
+	return targets, rows.Err()
+}
+
+// Store mengganti semua KPI periode itu di tabel sla_kpi dalam satu transaksi
+func Store(db *sql.DB, table string, period summary.Period, start time.Time, kpis []KPI) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM "+table+" WHERE period = ? AND period_start = ?", string(period), start); err != nil {
+		return fmt.Errorf("gagal menghapus KPI lama di %s: %w", table, err)
+	}
+
+	stmt, err := tx.Prepare(`
+        INSERT INTO ` + table + ` (period, period_start, ip_id, group_name, targets, observed_seconds,
+                                   incident_count, open_count, downtime_seconds, mttr_seconds, mtbf_seconds,
+                                   availability_percentage, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `)
+	if err != nil {
+		return fmt.Errorf("gagal mempersiapkan insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, k := range kpis {
+		var ipID sql.NullInt64
+		var group sql.NullString
+		if k.Group != "" {
+			group = sql.NullString{String: k.Group, Valid: true}
+		} else {
+			ipID = sql.NullInt64{Int64: int64(k.IPID), Valid: true}
+		}
+		_, err := stmt.Exec(string(period), start, ipID, group, k.Targets, k.Observed.Seconds(),
+			k.Incidents, k.Open, k.Downtime.Seconds(), k.MTTR, k.MTBF, k.Availability, now)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan KPI ke %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
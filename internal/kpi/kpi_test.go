@@ -0,0 +1,164 @@
+package kpi_test
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/incident"
+	"sla_uptime/internal/kpi"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/summary"
+	"sla_uptime/internal/testdb"
+)
+
+type want struct {
+	targets, incidents, open int
+	downtime                 float64 // detik
+	mttr, mtbf               float64 // NaN = NULL
+	availability             float64
+}
+
+var null = math.NaN()
+
+func hours(h float64) float64 { return h * 3600 }
+
+// Membandingkan KPI dengan want per kunci: "1", "2", "3" untuk ip_id, nama
+// grup untuk grup
+func checkKPIs(t *testing.T, name string, kpis []kpi.KPI, wants map[string]want) {
+	t.Helper()
+	got := make(map[string]kpi.KPI)
+	for _, k := range kpis {
+		key := k.Group
+		if key == "" {
+			key = string(rune('0' + k.IPID))
+		}
+		got[key] = k
+	}
+	if len(got) != len(wants) {
+		t.Errorf("%s: %d baris, seharusnya %d", name, len(got), len(wants))
+	}
+	for key, w := range wants {
+		k, ok := got[key]
+		if !ok {
+			t.Errorf("%s: tidak ada KPI untuk %s", name, key)
+			continue
+		}
+		if k.Targets != w.targets || k.Incidents != w.incidents || k.Open != w.open || k.Downtime.Seconds() != w.downtime {
+			t.Errorf("%s: %s target %d insiden %d terbuka %d downtime %s, seharusnya %d %d %d %vs",
+				name, key, k.Targets, k.Incidents, k.Open, k.Downtime, w.targets, w.incidents, w.open, w.downtime)
+		}
+		if !nullEqual(k.MTTR, w.mttr) || !nullEqual(k.MTBF, w.mtbf) || math.Abs(k.Availability-w.availability) > 1e-9 {
+			t.Errorf("%s: %s MTTR %+v MTBF %+v availability %v, seharusnya %v %v %v",
+				name, key, k.MTTR, k.MTBF, k.Availability, w.mttr, w.mtbf, w.availability)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return day.Add(d) }
+	ended := func(d time.Duration) sql.NullTime { return sql.NullTime{Time: at(d), Valid: true} }
+	inc := func(ipID int, started time.Duration, end sql.NullTime, lastSample time.Duration, class rules.Class) *incident.Incident {
+		return &incident.Incident{IPID: ipID, StartedAt: at(started), EndedAt: end, LastSampleAt: at(lastSample), Class: class}
+	}
+	incidents := []*incident.Incident{
+		// selesai sebelum periode: diabaikan
+		inc(1, -3*time.Hour, ended(-2*time.Hour), -2*time.Hour, rules.SLA),
+		// mulai sebelum periode: downtime di dalam periode dihitung, insidennya tidak
+		inc(1, -time.Hour, ended(time.Hour), time.Hour, rules.SLA),
+		inc(1, 10*time.Hour, ended(10*time.Hour+30*time.Minute), 10*time.Hour+30*time.Minute, rules.SLA),
+		inc(1, 20*time.Hour, ended(21*time.Hour), 21*time.Hour, rules.SLA),
+		// pekerjaan terjadwal dan excluded tidak dihitung sebagai down
+		inc(1, 22*time.Hour, ended(23*time.Hour), 23*time.Hour, rules.Scheduled),
+		inc(3, 2*time.Hour, ended(3*time.Hour), 3*time.Hour, rules.Excluded),
+		// masih berlangsung: lama sampai sampel gagal terakhir, tidak masuk MTTR
+		inc(2, 23*time.Hour, sql.NullTime{}, 23*time.Hour+45*time.Minute, rules.SLA),
+	}
+	// ip_id 3 tanpa insiden sla tetap dihitung karena punya ringkasan uptime,
+	// ip_id 4 di grup core tapi tidak punya ringkasan maupun insiden
+	targets := map[int]bool{3: true}
+	groups := map[int]string{1: "core", 2: "core", 4: "core"}
+
+	tests := []struct {
+		name string
+		end  time.Time
+		want map[string]want
+	}{
+		{"periode selesai", day.AddDate(0, 0, 1), map[string]want{
+			"1":    {1, 2, 0, hours(2.5), hours(0.75), hours(21.5) / 2, 21.5 / 24 * 100},
+			"2":    {1, 1, 1, hours(0.75), null, hours(23.25), 23.25 / 24 * 100},
+			"3":    {1, 0, 0, 0, null, null, 100},
+			"core": {2, 3, 1, hours(3.25), hours(0.75), hours(44.75) / 3, 44.75 / 48 * 100},
+		}},
+		// insiden yang mulai setelah observedEnd tidak dihitung
+		{"periode berjalan", day.Add(12 * time.Hour), map[string]want{
+			"1":    {1, 1, 0, hours(1.5), hours(0.5), hours(10.5), 10.5 / 12 * 100},
+			"3":    {1, 0, 0, 0, null, null, 100},
+			"core": {1, 1, 0, hours(1.5), hours(0.5), hours(10.5), 10.5 / 12 * 100},
+		}},
+		// insiden terbuka dipotong di observedEnd
+		{"di tengah insiden terbuka", day.Add(23*time.Hour + 30*time.Minute), map[string]want{
+			"1":    {1, 2, 0, hours(2.5), hours(0.75), hours(21) / 2, 21 / 23.5 * 100},
+			"2":    {1, 1, 1, hours(0.5), null, hours(23), 23 / 23.5 * 100},
+			"3":    {1, 0, 0, 0, null, null, 100},
+			"core": {2, 3, 1, hours(3), hours(0.75), hours(44) / 3, 44 / 47.0 * 100},
+		}},
+		{"periode belum mulai", day, map[string]want{}},
+	}
+	for _, tt := range tests {
+		checkKPIs(t, tt.name, kpi.Aggregate(targets, incidents, groups, day, tt.end), tt.want)
+	}
+}
+
+// Perhitungannya di TestAggregate, di sini hanya pembacaan target, insiden
+// dan grup dari database
+func TestCompute(t *testing.T) {
+	db, cfg := testdb.MySQL(t)
+	tables := cfg.Tables
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return day.Add(d) }
+
+	mustExec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.Exec(query, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustExec("INSERT INTO " + tables.IPMonitor + " (id, ip, group_name) VALUES (1, '10.0.0.1', 'core'), (2, '10.0.0.2', 'core'), (3, '10.0.0.3', NULL)")
+	// ip_id 3 hanya punya ringkasan uptime, yang di hari berikutnya tidak dihitung
+	mustExec("INSERT INTO "+tables.SummaryUptime+" (ip_id, timestamp) VALUES (3, ?), (2, ?)", at(time.Hour), at(25*time.Hour))
+
+	incident := func(ipID int, started time.Time, ended sql.NullTime, lastSample time.Time, class string) {
+		t.Helper()
+		mustExec("INSERT INTO "+tables.Incidents+` (ip_id, started_at, ended_at, last_sample_at, classification, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)`, ipID, started, ended, lastSample, class, started)
+	}
+	ended := func(t time.Time) sql.NullTime { return sql.NullTime{Time: t, Valid: true} }
+	incident(1, at(-time.Hour), ended(at(time.Hour)), at(time.Hour), "sla")
+	incident(1, at(20*time.Hour), ended(at(21*time.Hour)), at(21*time.Hour), "sla")
+	incident(1, at(22*time.Hour), ended(at(23*time.Hour)), at(23*time.Hour), "scheduled")
+
+	kpis, err := kpi.Compute(db, tables, summary.Daily, day, day.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkKPIs(t, "periode selesai", kpis, map[string]want{
+		"1":    {1, 1, 0, hours(2), hours(1), hours(22), 22 / 24.0 * 100},
+		"3":    {1, 0, 0, 0, null, null, 100},
+		"core": {1, 1, 0, hours(2), hours(1), hours(22), 22 / 24.0 * 100},
+	})
+
+	// Periode yang belum mulai tidak punya KPI
+	if kpis, err := kpi.Compute(db, tables, summary.Daily, day, day); err != nil || len(kpis) != 0 {
+		t.Errorf("periode belum mulai: %d baris (%v), seharusnya tidak ada", len(kpis), err)
+	}
+}
+
+func nullEqual(v sql.NullFloat64, want float64) bool {
+	if math.IsNaN(want) {
+		return !v.Valid
+	}
+	return v.Valid && math.Abs(v.Float64-want) < 1e-9
+}
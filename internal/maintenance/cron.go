@@ -0,0 +1,108 @@
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron adalah jadwal cron 5 kolom: menit jam tanggal bulan hari.
+// Setiap kolom menerima *, angka, rentang (1-5), daftar (1,3) dan langkah
+// (*/15, 0-30/10). Hari 0 dan 7 sama-sama Minggu. Seperti cron biasa, jika
+// tanggal dan hari sama-sama dibatasi, cukup salah satu yang cocok.
+type Cron struct {
+	expr                         string
+	minute, hour, dom, month     []bool
+	dow                          []bool
+	domRestricted, dowRestricted bool
+}
+
+// ParseCron membaca ekspresi cron 5 kolom
+func ParseCron(expr string) (*Cron, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron %q harus 5 kolom (menit jam tanggal bulan hari)", expr)
+	}
+
+	c := &Cron{expr: expr}
+	var err error
+	if c.minute, err = parseField(parts[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron %q kolom menit: %w", expr, err)
+	}
+	if c.hour, err = parseField(parts[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron %q kolom jam: %w", expr, err)
+	}
+	if c.dom, err = parseField(parts[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron %q kolom tanggal: %w", expr, err)
+	}
+	if c.month, err = parseField(parts[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron %q kolom bulan: %w", expr, err)
+	}
+	if c.dow, err = parseField(parts[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron %q kolom hari: %w", expr, err)
+	}
+	if c.dow[7] {
+		c.dow[0] = true
+	}
+	c.domRestricted = parts[2] != "*"
+	c.dowRestricted = parts[4] != "*"
+	return c, nil
+}
+
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Match mengembalikan true jika menit t (di zona waktu t) cocok dengan jadwal
+func (c *Cron) Match(t time.Time) bool {
+	if !c.minute[t.Minute()] || !c.hour[t.Hour()] || !c.month[int(t.Month())] {
+		return false
+	}
+	domMatch := c.dom[t.Day()]
+	dowMatch := c.dow[int(t.Weekday())]
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// Satu kolom cron menjadi tabel nilai yang cocok, index = nilai
+func parseField(field string, min, max int) ([]bool, error) {
+	values := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("langkah %q tidak valid", part[i+1:])
+			}
+			step = n
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("nilai %q tidak valid", bounds[0])
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("nilai %q tidak valid", bounds[1])
+				}
+			} else if step > 1 {
+				hi = max // contoh 5/15 = 5, 20, 35, 50
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("rentang %d-%d di luar %d-%d", lo, hi, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
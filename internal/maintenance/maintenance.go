@@ -0,0 +1,196 @@
+// Package maintenance membaca jendela maintenance (sekali jalan atau berulang
+// dengan cron) dari tabel maintenance_windows dan menentukan apakah satu target
+// sedang maintenance pada waktu tertentu.
+package maintenance
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/target"
+)
+
+// Window adalah satu baris maintenance_windows
+type Window struct {
+	ID       int
+	IPID     sql.NullInt64  // NULL = tidak dibatasi per target
+	Group    sql.NullString // NULL = tidak dibatasi per grup
+	StartsAt sql.NullTime
+	EndsAt   sql.NullTime
+	Cron     *Cron // nil untuk jendela sekali jalan
+	Duration time.Duration
+	Note     string
+}
+
+// Target mengembalikan untuk siapa jendela ini berlaku
+func (w Window) Target() string {
+	switch {
+	case w.IPID.Valid:
+		return fmt.Sprintf("ip_id %d", w.IPID.Int64)
+	case w.Group.Valid:
+		return "grup " + w.Group.String
+	default:
+		return "semua target"
+	}
+}
+
+// Interval adalah satu kejadian maintenance [Start, End)
+type Interval struct {
+	Window     *Window
+	Start, End time.Time
+}
+
+// Schedule berisi semua kejadian maintenance dalam satu rentang waktu
+type Schedule struct {
+	intervals []Interval
+	groups    map[int]string // ip_id -> group_name dari ip_monitor
+}
+
+// Load membaca maintenance_windows dan menjabarkan semua kejadiannya yang
+// beririsan dengan [from, to). starts_at / ends_at disimpan dalam UTC, cron
+// dievaluasi di zona waktu loc (report.timezone). Jendela yang tidak valid
+// dilewati dengan log supaya satu baris salah tidak menghentikan summarize.
+func Load(db *sql.DB, tables config.TablesConfig, loc *time.Location, from, to time.Time) (*Schedule, error) {
+	windows, err := loadWindows(db, tables.MaintenanceWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schedule{}
+	for i := range windows {
+		s.intervals = append(s.intervals, windows[i].occurrences(loc, from, to)...)
+	}
+	sort.Slice(s.intervals, func(i, j int) bool { return s.intervals[i].Start.Before(s.intervals[j].Start) })
+	if len(s.intervals) == 0 {
+		return s, nil
+	}
+
+	if s.groups, err = target.Groups(db, tables.IPMonitor); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func loadWindows(db *sql.DB, table string) ([]Window, error) {
+	rows, err := db.Query(`
+        SELECT id, ip_id, group_name, starts_at, ends_at, COALESCE(cron, ''), COALESCE(duration_minutes, 0), COALESCE(note, '')
+        FROM ` + table)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var windows []Window
+	for rows.Next() {
+		var w Window
+		var cron string
+		var minutes int
+		if err := rows.Scan(&w.ID, &w.IPID, &w.Group, &w.StartsAt, &w.EndsAt, &cron, &minutes, &w.Note); err != nil {
+			return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		w.Duration = time.Duration(minutes) * time.Minute
+
+		if cron != "" {
+			if w.Cron, err = ParseCron(cron); err != nil {
+				log.Printf("Jendela maintenance %d dilewati: %v", w.ID, err)
+				continue
+			}
+			if w.Duration <= 0 {
+				log.Printf("Jendela maintenance %d dilewati: duration_minutes wajib diisi untuk cron", w.ID)
+				continue
+			}
+		} else if !w.StartsAt.Valid || !w.EndsAt.Valid || !w.EndsAt.Time.After(w.StartsAt.Time) {
+			log.Printf("Jendela maintenance %d dilewati: starts_at dan ends_at wajib diisi (ends_at setelah starts_at) jika cron kosong", w.ID)
+			continue
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+// Kejadian jendela yang beririsan dengan [from, to). Untuk cron, setiap menit
+// (di zona waktu loc) yang cocok dalam masa berlaku (starts_at / ends_at)
+// memulai satu kejadian sepanjang Duration.
+func (w *Window) occurrences(loc *time.Location, from, to time.Time) []Interval {
+	if w.Cron == nil {
+		if w.StartsAt.Time.Before(to) && w.EndsAt.Time.After(from) {
+			return []Interval{{Window: w, Start: w.StartsAt.Time, End: w.EndsAt.Time}}
+		}
+		return nil
+	}
+
+	var out []Interval
+	start := from.Add(-w.Duration).In(loc).Truncate(time.Minute)
+	for t := start; t.Before(to); t = t.Add(time.Minute) {
+		if w.StartsAt.Valid && t.Before(w.StartsAt.Time) {
+			continue
+		}
+		if w.EndsAt.Valid && !t.Before(w.EndsAt.Time) {
+			break
+		}
+		if !w.Cron.Match(t) {
+			continue
+		}
+		end := t.Add(w.Duration)
+		if w.EndsAt.Valid && end.After(w.EndsAt.Time) {
+			end = w.EndsAt.Time
+		}
+		if end.After(from) {
+			out = append(out, Interval{Window: w, Start: t, End: end})
+		}
+	}
+	return out
+}
+
+func (s *Schedule) applies(w *Window, ipID int) bool {
+	switch {
+	case w.IPID.Valid:
+		return int(w.IPID.Int64) == ipID
+	case w.Group.Valid:
+		return s.groups[ipID] == w.Group.String
+	default:
+		return true
+	}
+}
+
+// Active mengembalikan true jika ipID sedang maintenance pada waktu at
+func (s *Schedule) Active(ipID int, at time.Time) bool {
+	if s == nil {
+		return false
+	}
+	for _, iv := range s.intervals {
+		if iv.Start.After(at) {
+			break
+		}
+		if at.Before(iv.End) && s.applies(iv.Window, ipID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps mengembalikan true jika ada maintenance (target manapun) yang
+// beririsan dengan [from, to)
+func (s *Schedule) Overlaps(from, to time.Time) bool {
+	if s == nil {
+		return false
+	}
+	for _, iv := range s.intervals {
+		if iv.Start.Before(to) && iv.End.After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// Intervals mengembalikan semua kejadian maintenance, urut dari yang paling awal
+func (s *Schedule) Intervals() []Interval {
+	if s == nil {
+		return nil
+	}
+	return s.intervals
+}
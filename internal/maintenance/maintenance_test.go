@@ -0,0 +1,160 @@
+package maintenance
+
+import (
+	"database/sql"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  bool
+	}{
+		{"* * * * *", false},
+		{"0 2 * * 0", false},
+		{"*/15 0-6 1,15 * 1-5", false},
+		{"5/20 * * * *", false},
+		{"0 0 * * 7", false},
+		{"0 2 * *", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * * 13 *", true},
+		{"* * * * 8", true},
+		{"*/0 * * * *", true},
+		{"5-1 * * * *", true},
+		{"a * * * *", true},
+	}
+	for _, tt := range tests {
+		_, err := ParseCron(tt.expr)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseCron(%q): error %v, seharusnya error = %v", tt.expr, err, tt.err)
+		}
+	}
+}
+
+func TestCronMatch(t *testing.T) {
+	// 2024-01-07 hari Minggu
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"0 2 * * 0", at(7, 2, 0), true},
+		{"0 2 * * 7", at(7, 2, 0), true},
+		{"0 2 * * 0", at(8, 2, 0), false},
+		{"0 2 * * 0", at(7, 2, 1), false},
+		{"*/15 * * * *", at(8, 10, 45), true},
+		{"*/15 * * * *", at(8, 10, 50), false},
+		{"5/20 * * * *", at(8, 10, 25), true},
+		{"5/20 * * * *", at(8, 10, 20), false},
+		{"0-30/10 * * * *", at(8, 10, 30), true},
+		{"0-30/10 * * * *", at(8, 10, 40), false},
+		{"0 0 1,15 * *", at(15, 0, 0), true},
+		{"0 0 * 2 *", at(15, 0, 0), false},
+		// tanggal dan hari sama-sama dibatasi: cukup salah satu
+		{"0 0 1 * 0", at(7, 0, 0), true},
+		{"0 0 1 * 0", at(1, 0, 0), true},
+		{"0 0 1 * 0", at(8, 0, 0), false},
+		// hanya tanggal dibatasi: hari * tidak membuat semua hari cocok
+		{"0 0 1 * *", at(7, 0, 0), false},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Match(tt.t); got != tt.want {
+			t.Errorf("%q Match(%s) = %v, seharusnya %v", tt.expr, tt.t.Format("Mon 2006-01-02 15:04"), got, tt.want)
+		}
+	}
+}
+
+// Schedule dari windows seperti Load, tanpa database
+func newSchedule(t *testing.T, loc *time.Location, from, to time.Time, groups map[int]string, windows ...Window) *Schedule {
+	t.Helper()
+	s := &Schedule{groups: groups}
+	for i := range windows {
+		s.intervals = append(s.intervals, windows[i].occurrences(loc, from, to)...)
+	}
+	sort.Slice(s.intervals, func(i, j int) bool { return s.intervals[i].Start.Before(s.intervals[j].Start) })
+	return s
+}
+
+func mustCron(t *testing.T, expr string) *Cron {
+	t.Helper()
+	c, err := ParseCron(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func validTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+func TestScheduleActive(t *testing.T) {
+	day := time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC) // Minggu
+	jakarta := time.FixedZone("UTC+7", 7*3600)
+
+	s := newSchedule(t, jakarta, day.Add(-12*time.Hour), day.Add(48*time.Hour), map[int]string{2: "core", 3: "edge"},
+		// sekali jalan untuk ip_id 1, 10:00-11:00 UTC
+		Window{ID: 1, IPID: sql.NullInt64{Int64: 1, Valid: true},
+			StartsAt: validTime(day.Add(10 * time.Hour)), EndsAt: validTime(day.Add(11 * time.Hour))},
+		// setiap Minggu 02:00 waktu Jakarta (19:00 UTC Sabtu) selama 2 jam untuk grup core
+		Window{ID: 2, Group: sql.NullString{String: "core", Valid: true}, Cron: mustCron(t, "0 2 * * 0"), Duration: 2 * time.Hour},
+		// setiap hari 23:30 UTC (06:30 Jakarta) selama 1 jam untuk semua target,
+		// hanya sampai 8 Januari 00:00 UTC
+		Window{ID: 3, Cron: mustCron(t, "30 6 * * *"), Duration: time.Hour, EndsAt: validTime(day.Add(24 * time.Hour))},
+	)
+
+	tests := []struct {
+		name string
+		ipID int
+		at   time.Time
+		want bool
+	}{
+		{"sekali jalan, awal termasuk", 1, day.Add(10 * time.Hour), true},
+		{"sekali jalan, akhir tidak termasuk", 1, day.Add(11 * time.Hour), false},
+		{"sekali jalan, target lain", 2, day.Add(10 * time.Hour), false},
+		{"cron grup di zona report", 2, day.Add(-5*time.Hour + 30*time.Minute), true},
+		{"cron grup, grup lain", 3, day.Add(-5*time.Hour + 30*time.Minute), false},
+		{"cron grup, minggu berikutnya belum", 2, day.Add(24*time.Hour - 5*time.Hour), false},
+		{"cron semua target", 3, day.Add(23*time.Hour + 45*time.Minute), true},
+		{"cron dipotong ends_at", 3, day.Add(24*time.Hour + 15*time.Minute), false},
+		{"di luar semua jendela", 1, day.Add(12 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := s.Active(tt.ipID, tt.at); got != tt.want {
+			t.Errorf("%s: Active(%d, %s) = %v, seharusnya %v", tt.name, tt.ipID, tt.at.Format(time.RFC3339), got, tt.want)
+		}
+	}
+
+	var nilSchedule *Schedule
+	if nilSchedule.Active(1, day) {
+		t.Errorf("Schedule nil tidak boleh aktif")
+	}
+}
+
+// Kejadian cron yang mulai sebelum from tapi masih berjalan ikut dijabarkan
+func TestOccurrencesBeforeFrom(t *testing.T) {
+	from := time.Date(2024, 1, 7, 3, 0, 0, 0, time.UTC)
+	w := Window{ID: 1, Cron: mustCron(t, "0 2 * * *"), Duration: 2 * time.Hour}
+	got := w.occurrences(time.UTC, from, from.Add(time.Hour))
+	if len(got) != 1 || !got[0].Start.Equal(from.Add(-time.Hour)) || !got[0].End.Equal(from.Add(time.Hour)) {
+		t.Fatalf("occurrences = %+v, seharusnya satu kejadian 02:00-04:00", got)
+	}
+	s := newSchedule(t, time.UTC, from, from.Add(time.Hour), nil, w)
+	if !s.Active(9, from.Add(30*time.Minute)) {
+		t.Errorf("kejadian 02:00-04:00 seharusnya aktif pukul 03:30")
+	}
+	if !s.Overlaps(from, from.Add(time.Hour)) || s.Overlaps(from.Add(time.Hour), from.Add(2*time.Hour)) {
+		t.Errorf("Overlaps tidak sesuai kejadian 02:00-04:00")
+	}
+}
@@ -0,0 +1,286 @@
+// Package migrate mengelola skema database (MySQL dan SQLite lokal) lewat
+// migrasi bernomor yang ikut ter-embed di binary.
+//
+// Migrasi SQL ada di folder mysql/ dan sqlite/ dengan nama
+// NNNN_nama.up.sql dan NNNN_nama.down.sql. Nama tabel ditulis sebagai
+// template, contoh {{.PingResults}}, dan diisi dari konfigurasi tables.
+// Migrasi yang butuh logika (misalnya tambah kolom hanya jika belum ada)
+// didaftarkan sebagai fungsi Go di goMigrations.
+package migrate
+
+import (
+	"bytes"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"sla_uptime/internal/config"
+)
+
+// Dialect database yang didukung
+type Dialect string
+
+const (
+	MySQL  Dialect = "mysql"
+	SQLite Dialect = "sqlite"
+)
+
+// Tabel pencatat versi migrasi yang sudah dijalankan
+const versionTable = "schema_migrations"
+
+//go:embed mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+// Migration adalah satu langkah perubahan skema
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(db *sql.DB, cfg *config.Config) error
+	Down    func(db *sql.DB, cfg *config.Config) error
+}
+
+// Status satu migrasi, AppliedAt nol berarti belum dijalankan
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+var fileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migrations mengembalikan semua migrasi untuk dialect tertentu, urut versi
+func Migrations(dialect Dialect) ([]Migration, error) {
+	byVersion := make(map[int]*Migration)
+	get := func(version int, name string) (*Migration, error) {
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migrasi %s versi %d punya dua nama: %s dan %s", dialect, version, m.Name, name)
+		}
+		return m, nil
+	}
+
+	entries, err := fs.ReadDir(files, string(dialect))
+	if err != nil {
+		return nil, fmt.Errorf("dialect tidak dikenal: %s", dialect)
+	}
+	for _, entry := range entries {
+		match := fileRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nama file migrasi tidak valid: %s/%s", dialect, entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		m, err := get(version, match[2])
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := files.ReadFile(path.Join(string(dialect), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		run := sqlMigration(entry.Name(), string(content))
+		if match[3] == "up" {
+			m.Up = run
+		} else {
+			m.Down = run
+		}
+	}
+
+	for _, gm := range goMigrations[dialect] {
+		m, err := get(gm.Version, gm.Name)
+		if err != nil {
+			return nil, err
+		}
+		if m.Up != nil || m.Down != nil {
+			return nil, fmt.Errorf("migrasi %s versi %d ada di file SQL dan di Go", dialect, gm.Version)
+		}
+		m.Up, m.Down = gm.Up, gm.Down
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil {
+			return nil, fmt.Errorf("migrasi %s versi %d (%s) tidak punya langkah up", dialect, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up menjalankan semua migrasi yang belum dijalankan dan mengembalikan yang baru diterapkan
+func Up(db *sql.DB, dialect Dialect, cfg *config.Config) ([]Migration, error) {
+	migrations, applied, err := load(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := m.Up(db, cfg); err != nil {
+			return done, fmt.Errorf("migrasi %04d_%s gagal: %w", m.Version, m.Name, err)
+		}
+		_, err := db.Exec("INSERT INTO "+versionTable+" (version, name, applied_at) VALUES (?, ?, ?)",
+			m.Version, m.Name, time.Now())
+		if err != nil {
+			return done, fmt.Errorf("gagal mencatat migrasi %04d_%s: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down membatalkan sejumlah steps migrasi terakhir yang sudah dijalankan
+func Down(db *sql.DB, dialect Dialect, cfg *config.Config, steps int) ([]Migration, error) {
+	migrations, applied, err := load(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return done, fmt.Errorf("migrasi %04d_%s tidak bisa dibatalkan (tidak ada langkah down)", m.Version, m.Name)
+		}
+		if err := m.Down(db, cfg); err != nil {
+			return done, fmt.Errorf("batal migrasi %04d_%s gagal: %w", m.Version, m.Name, err)
+		}
+		if _, err := db.Exec("DELETE FROM "+versionTable+" WHERE version = ?", m.Version); err != nil {
+			return done, fmt.Errorf("gagal menghapus catatan migrasi %04d_%s: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// List mengembalikan status semua migrasi
+func List(db *sql.DB, dialect Dialect) ([]Status, error) {
+	migrations, applied, err := load(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, Status{Version: m.Version, Name: m.Name, AppliedAt: applied[m.Version]})
+	}
+	return statuses, nil
+}
+
+// Check mengembalikan error jika masih ada migrasi yang belum dijalankan,
+// dipakai subcommand lain supaya tidak jalan di atas skema lama
+func Check(db *sql.DB, dialect Dialect) error {
+	statuses, err := List(db, dialect)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, s := range statuses {
+		if s.AppliedAt.IsZero() {
+			pending = append(pending, fmt.Sprintf("%04d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("skema %s belum terbaru, migrasi belum dijalankan: %s (jalankan: slauptime migrate up -db %s)",
+			dialect, strings.Join(pending, ", "), dialect)
+	}
+	return nil
+}
+
+// Membaca daftar migrasi dan versi yang sudah dijalankan
+func load(db *sql.DB, dialect Dialect) ([]Migration, map[int]time.Time, error) {
+	migrations, err := Migrations(dialect)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal membuat tabel %s: %w", versionTable, err)
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM " + versionTable)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal membaca tabel %s: %w", versionTable, err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, nil, fmt.Errorf("gagal membaca tabel %s: %w", versionTable, err)
+		}
+		applied[version] = appliedAt
+	}
+	return migrations, applied, rows.Err()
+}
+
+// Migrasi dari file SQL: template nama tabel diisi lalu setiap statement dijalankan berurutan
+func sqlMigration(name, content string) func(db *sql.DB, cfg *config.Config) error {
+	return func(db *sql.DB, cfg *config.Config) error {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, cfg.Tables); err != nil {
+			return err
+		}
+
+		for _, stmt := range splitStatements(buf.String()) {
+			if _, err := db.Exec(stmt); err != nil {
+				return fmt.Errorf("%w\n%s", err, stmt)
+			}
+		}
+		return nil
+	}
+}
+
+// Memecah isi file menjadi statement, setiap statement diakhiri ";" di akhir baris
+func splitStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
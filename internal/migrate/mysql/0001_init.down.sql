@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS {{.UptimeSummary}};
+DROP TABLE IF EXISTS {{.SummaryDowntime}};
+DROP TABLE IF EXISTS {{.SummaryUptime}};
+DROP TABLE IF EXISTS {{.PingResults}};
+DROP TABLE IF EXISTS {{.IPMonitor}};
@@ -0,0 +1,61 @@
+-- Skema dasar semua tabel yang dipakai slauptime.
+-- Memakai IF NOT EXISTS supaya database lama yang tabelnya sudah ada tetap aman,
+-- kolom dan index yang belum ada di database lama ditambahkan oleh migrasi 0002.
+
+CREATE TABLE IF NOT EXISTS {{.IPMonitor}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip VARCHAR(255) NOT NULL,
+    status_id INT,
+    reason_id INT,
+    check_type VARCHAR(16) NOT NULL DEFAULT 'icmp',
+    check_params TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS {{.PingResults}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT,
+    timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(1),
+    response_time FLOAT,
+    status_id INT,
+    reason_id INT,
+    packet_loss FLOAT,
+    jitter FLOAT,
+    rtt_min FLOAT,
+    rtt_max FLOAT,
+    rtt_stddev FLOAT,
+    retry_count INT DEFAULT 0,
+    INDEX idx_ip_timestamp (ip_id, timestamp)
+);
+
+CREATE TABLE IF NOT EXISTS {{.SummaryUptime}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NOT NULL,
+    timestamp DATETIME NOT NULL,
+    uptime_percentage FLOAT,
+    success_count INT,
+    fail_count INT,
+    response_time FLOAT,
+    UNIQUE KEY uniq_ip_timestamp (ip_id, timestamp)
+);
+
+CREATE TABLE IF NOT EXISTS {{.SummaryDowntime}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NOT NULL,
+    timestamp DATETIME NOT NULL,
+    success_count INT,
+    fail_count INT,
+    response_time FLOAT,
+    UNIQUE KEY uniq_ip_timestamp (ip_id, timestamp)
+);
+
+CREATE TABLE IF NOT EXISTS {{.UptimeSummary}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NOT NULL,
+    timestamp DATETIME NOT NULL,
+    uptime_percentage FLOAT,
+    success_count INT,
+    fail_count INT,
+    response_time FLOAT,
+    INDEX idx_ip_timestamp (ip_id, timestamp)
+);
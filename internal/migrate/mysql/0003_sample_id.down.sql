@@ -0,0 +1,3 @@
+ALTER TABLE {{.PingResults}}
+    DROP INDEX uniq_sample_id,
+    DROP COLUMN sample_id;
@@ -0,0 +1,6 @@
+-- sample_id unik per hasil probe, supaya replay dari spool lokal (INSERT IGNORE)
+-- tidak menulis baris yang sama dua kali. Baris lama tetap NULL.
+
+ALTER TABLE {{.PingResults}}
+    ADD COLUMN sample_id CHAR(36) NULL,
+    ADD UNIQUE KEY uniq_sample_id (sample_id);
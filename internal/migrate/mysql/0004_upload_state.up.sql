@@ -0,0 +1,8 @@
+-- Watermark upload ping_results dari SQLite lokal per sumber (prober).
+-- last_id adalah id SQLite terakhir yang sudah tersimpan di MySQL.
+
+CREATE TABLE IF NOT EXISTS {{.UploadState}} (
+    source VARCHAR(255) NOT NULL PRIMARY KEY,
+    last_id BIGINT NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Catatan setiap kali summarize meringkas satu jam (run ledger), dipakai untuk
+-- mendeteksi jam yang terlewat dan jam yang sudah selesai.
+
+CREATE TABLE IF NOT EXISTS {{.SummaryRuns}} (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL,
+    hour DATETIME NOT NULL,
+    run_type VARCHAR(16) NOT NULL,
+    status VARCHAR(8) NOT NULL,
+    row_count INT NOT NULL DEFAULT 0,
+    error TEXT NULL,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    INDEX idx_kind_hour (kind, hour)
+);
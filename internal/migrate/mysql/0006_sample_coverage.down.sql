@@ -0,0 +1,14 @@
+ALTER TABLE {{.UptimeSummary}}
+    DROP COLUMN coverage_percentage,
+    DROP COLUMN unknown_count,
+    DROP COLUMN expected_count;
+
+ALTER TABLE {{.SummaryDowntime}}
+    DROP COLUMN coverage_percentage,
+    DROP COLUMN unknown_count,
+    DROP COLUMN expected_count;
+
+ALTER TABLE {{.SummaryUptime}}
+    DROP COLUMN coverage_percentage,
+    DROP COLUMN unknown_count,
+    DROP COLUMN expected_count;
@@ -0,0 +1,17 @@
+-- Jumlah sampel yang seharusnya ada (dari probe.interval), yang tidak tercatat,
+-- dan persentase sampel yang tercatat per jam.
+
+ALTER TABLE {{.SummaryUptime}}
+    ADD COLUMN expected_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN unknown_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN coverage_percentage FLOAT NULL;
+
+ALTER TABLE {{.SummaryDowntime}}
+    ADD COLUMN expected_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN unknown_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN coverage_percentage FLOAT NULL;
+
+ALTER TABLE {{.UptimeSummary}}
+    ADD COLUMN expected_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN unknown_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN coverage_percentage FLOAT NULL;
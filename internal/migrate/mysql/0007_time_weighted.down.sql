@@ -0,0 +1,14 @@
+ALTER TABLE {{.UptimeSummary}}
+    DROP COLUMN unknown_seconds,
+    DROP COLUMN down_seconds,
+    DROP COLUMN up_seconds;
+
+ALTER TABLE {{.SummaryDowntime}}
+    DROP COLUMN unknown_seconds,
+    DROP COLUMN down_seconds,
+    DROP COLUMN up_seconds;
+
+ALTER TABLE {{.SummaryUptime}}
+    DROP COLUMN unknown_seconds,
+    DROP COLUMN down_seconds,
+    DROP COLUMN up_seconds;
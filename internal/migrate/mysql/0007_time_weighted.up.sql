@@ -0,0 +1,17 @@
+-- Lama up / down / unknown per jam (detik), dihitung dari selisih antar sampel.
+-- Baris lama tetap 0, report memakai jumlah sampel untuk baris tersebut.
+
+ALTER TABLE {{.SummaryUptime}}
+    ADD COLUMN up_seconds FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN down_seconds FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN unknown_seconds FLOAT NOT NULL DEFAULT 0;
+
+ALTER TABLE {{.SummaryDowntime}}
+    ADD COLUMN up_seconds FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN down_seconds FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN unknown_seconds FLOAT NOT NULL DEFAULT 0;
+
+ALTER TABLE {{.UptimeSummary}}
+    ADD COLUMN up_seconds FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN down_seconds FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN unknown_seconds FLOAT NOT NULL DEFAULT 0;
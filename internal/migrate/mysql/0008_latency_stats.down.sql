@@ -0,0 +1,29 @@
+ALTER TABLE {{.UptimeSummary}}
+    DROP COLUMN rt_histogram,
+    DROP COLUMN rt_p99,
+    DROP COLUMN rt_p95,
+    DROP COLUMN rt_p90,
+    DROP COLUMN rt_p50,
+    DROP COLUMN rt_mean,
+    DROP COLUMN rt_max,
+    DROP COLUMN rt_min;
+
+ALTER TABLE {{.SummaryDowntime}}
+    DROP COLUMN rt_histogram,
+    DROP COLUMN rt_p99,
+    DROP COLUMN rt_p95,
+    DROP COLUMN rt_p90,
+    DROP COLUMN rt_p50,
+    DROP COLUMN rt_mean,
+    DROP COLUMN rt_max,
+    DROP COLUMN rt_min;
+
+ALTER TABLE {{.SummaryUptime}}
+    DROP COLUMN rt_histogram,
+    DROP COLUMN rt_p99,
+    DROP COLUMN rt_p95,
+    DROP COLUMN rt_p90,
+    DROP COLUMN rt_p50,
+    DROP COLUMN rt_mean,
+    DROP COLUMN rt_max,
+    DROP COLUMN rt_min;
@@ -0,0 +1,32 @@
+-- Statistik response time (ms) dari sampel sukses saja, NULL jika tidak ada.
+-- rt_histogram berisi JSON {"le": [batas bucket], "counts": [jumlah per bucket]}.
+
+ALTER TABLE {{.SummaryUptime}}
+    ADD COLUMN rt_min FLOAT NULL,
+    ADD COLUMN rt_max FLOAT NULL,
+    ADD COLUMN rt_mean FLOAT NULL,
+    ADD COLUMN rt_p50 FLOAT NULL,
+    ADD COLUMN rt_p90 FLOAT NULL,
+    ADD COLUMN rt_p95 FLOAT NULL,
+    ADD COLUMN rt_p99 FLOAT NULL,
+    ADD COLUMN rt_histogram TEXT NULL;
+
+ALTER TABLE {{.SummaryDowntime}}
+    ADD COLUMN rt_min FLOAT NULL,
+    ADD COLUMN rt_max FLOAT NULL,
+    ADD COLUMN rt_mean FLOAT NULL,
+    ADD COLUMN rt_p50 FLOAT NULL,
+    ADD COLUMN rt_p90 FLOAT NULL,
+    ADD COLUMN rt_p95 FLOAT NULL,
+    ADD COLUMN rt_p99 FLOAT NULL,
+    ADD COLUMN rt_histogram TEXT NULL;
+
+ALTER TABLE {{.UptimeSummary}}
+    ADD COLUMN rt_min FLOAT NULL,
+    ADD COLUMN rt_max FLOAT NULL,
+    ADD COLUMN rt_mean FLOAT NULL,
+    ADD COLUMN rt_p50 FLOAT NULL,
+    ADD COLUMN rt_p90 FLOAT NULL,
+    ADD COLUMN rt_p95 FLOAT NULL,
+    ADD COLUMN rt_p99 FLOAT NULL,
+    ADD COLUMN rt_histogram TEXT NULL;
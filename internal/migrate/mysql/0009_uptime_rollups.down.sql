@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS {{.SummaryUptimeMonthly}};
+DROP TABLE IF EXISTS {{.SummaryUptimeWeekly}};
+DROP TABLE IF EXISTS {{.SummaryUptimeDaily}};
@@ -0,0 +1,68 @@
+-- Rollup harian, mingguan (mulai Senin) dan bulanan dari summary_uptime.
+-- Dijumlahkan dari jumlah sampel dan detik up/down per jam, bukan rata-rata persentase.
+
+CREATE TABLE IF NOT EXISTS {{.SummaryUptimeDaily}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NOT NULL,
+    period_start DATETIME NOT NULL,
+    hours INT NOT NULL,
+    success_count INT NOT NULL,
+    fail_count INT NOT NULL,
+    expected_count INT NOT NULL,
+    unknown_count INT NOT NULL,
+    up_seconds DOUBLE NOT NULL,
+    down_seconds DOUBLE NOT NULL,
+    unknown_seconds DOUBLE NOT NULL,
+    uptime_percentage DOUBLE NOT NULL,
+    coverage_percentage DOUBLE NOT NULL,
+    rt_min FLOAT NULL,
+    rt_max FLOAT NULL,
+    rt_mean FLOAT NULL,
+    rt_histogram TEXT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY uniq_ip_period (ip_id, period_start)
+);
+
+CREATE TABLE IF NOT EXISTS {{.SummaryUptimeWeekly}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NOT NULL,
+    period_start DATETIME NOT NULL,
+    hours INT NOT NULL,
+    success_count INT NOT NULL,
+    fail_count INT NOT NULL,
+    expected_count INT NOT NULL,
+    unknown_count INT NOT NULL,
+    up_seconds DOUBLE NOT NULL,
+    down_seconds DOUBLE NOT NULL,
+    unknown_seconds DOUBLE NOT NULL,
+    uptime_percentage DOUBLE NOT NULL,
+    coverage_percentage DOUBLE NOT NULL,
+    rt_min FLOAT NULL,
+    rt_max FLOAT NULL,
+    rt_mean FLOAT NULL,
+    rt_histogram TEXT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY uniq_ip_period (ip_id, period_start)
+);
+
+CREATE TABLE IF NOT EXISTS {{.SummaryUptimeMonthly}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NOT NULL,
+    period_start DATETIME NOT NULL,
+    hours INT NOT NULL,
+    success_count INT NOT NULL,
+    fail_count INT NOT NULL,
+    expected_count INT NOT NULL,
+    unknown_count INT NOT NULL,
+    up_seconds DOUBLE NOT NULL,
+    down_seconds DOUBLE NOT NULL,
+    unknown_seconds DOUBLE NOT NULL,
+    uptime_percentage DOUBLE NOT NULL,
+    coverage_percentage DOUBLE NOT NULL,
+    rt_min FLOAT NULL,
+    rt_max FLOAT NULL,
+    rt_mean FLOAT NULL,
+    rt_histogram TEXT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY uniq_ip_period (ip_id, period_start)
+);
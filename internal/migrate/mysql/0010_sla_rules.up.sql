@@ -0,0 +1,12 @@
+-- Aturan klasifikasi sampel berdasarkan status_id / reason_id, dibaca summarize
+-- dan upload setiap kali jalan. NULL di status_id atau reason_id berarti semua
+-- nilai. Selama tabel ini kosong, aturan diambil dari filters di konfigurasi.
+
+CREATE TABLE IF NOT EXISTS {{.SLARules}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    status_id INT NULL,
+    reason_id INT NULL,
+    class VARCHAR(16) NOT NULL,
+    note VARCHAR(255) NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS {{.MaintenanceWindows}};
+
+ALTER TABLE {{.IPMonitor}}
+    DROP COLUMN group_name;
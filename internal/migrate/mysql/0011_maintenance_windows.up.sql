@@ -0,0 +1,22 @@
+-- Jendela maintenance: sampel di dalam jendela otomatis dihitung sebagai
+-- downtime terjadwal. Berlaku untuk satu target (ip_id), satu grup
+-- (group_name di ip_monitor), atau semua target jika keduanya NULL.
+-- Sekali jalan: starts_at dan ends_at (UTC). Berulang: cron (5 kolom, dalam
+-- zona report.timezone) dan duration_minutes, starts_at / ends_at opsional
+-- sebagai masa berlaku.
+
+ALTER TABLE {{.IPMonitor}}
+    ADD COLUMN group_name VARCHAR(255) NULL;
+
+CREATE TABLE IF NOT EXISTS {{.MaintenanceWindows}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NULL,
+    group_name VARCHAR(255) NULL,
+    starts_at DATETIME NULL,
+    ends_at DATETIME NULL,
+    cron VARCHAR(64) NULL,
+    duration_minutes INT NULL,
+    note VARCHAR(255) NULL,
+    INDEX idx_ip_id (ip_id),
+    INDEX idx_group_name (group_name)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS {{.IncidentTargets}};
+DROP TABLE IF EXISTS {{.IncidentState}};
+DROP TABLE IF EXISTS {{.Incidents}};
@@ -0,0 +1,34 @@
+-- Insiden down per target dari transisi status di ping_results. ended_at NULL
+-- berarti insiden masih berlangsung; duration_seconds insiden terbuka dihitung
+-- sampai sampel gagal terakhir (last_sample_at) dan diperbarui setiap build.
+
+CREATE TABLE IF NOT EXISTS {{.Incidents}} (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NOT NULL,
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME NULL,
+    last_sample_at DATETIME NOT NULL,
+    duration_seconds INT NOT NULL DEFAULT 0,
+    classification VARCHAR(16) NOT NULL,
+    status_id INT NULL,
+    reason_id INT NULL,
+    fail_count INT NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_ip_started (ip_id, started_at),
+    INDEX idx_ended_at (ended_at)
+);
+
+-- Posisi terakhir (ping_results.id) yang sudah diproses incident builder
+CREATE TABLE IF NOT EXISTS {{.IncidentState}} (
+    name VARCHAR(64) PRIMARY KEY,
+    last_id BIGINT NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL
+);
+
+-- Waktu sampel terakhir yang sudah diproses per target, sampel yang datang
+-- terlambat dan lebih tua dari ini dilewati supaya tidak membuka insiden lama
+CREATE TABLE IF NOT EXISTS {{.IncidentTargets}} (
+    ip_id INT PRIMARY KEY,
+    last_sample_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
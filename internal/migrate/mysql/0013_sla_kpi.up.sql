@@ -0,0 +1,22 @@
+-- KPI per periode (daily / weekly / monthly) dari tabel incidents, satu baris
+-- per ip_id (group_name NULL) dan satu baris per grup (ip_id NULL).
+-- Hanya insiden berklasifikasi sla yang dihitung.
+
+CREATE TABLE IF NOT EXISTS {{.SLAKPI}} (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    period VARCHAR(8) NOT NULL,
+    period_start DATETIME NOT NULL,
+    ip_id INT NULL,
+    group_name VARCHAR(255) NULL,
+    targets INT NOT NULL,
+    observed_seconds DOUBLE NOT NULL,
+    incident_count INT NOT NULL,
+    open_count INT NOT NULL,
+    downtime_seconds DOUBLE NOT NULL,
+    mttr_seconds DOUBLE NULL,
+    mtbf_seconds DOUBLE NULL,
+    availability_percentage DOUBLE NOT NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_period_start (period, period_start),
+    INDEX idx_ip_id (ip_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.SLAAttainment}};
+DROP TABLE IF EXISTS {{.SLATargets}};
@@ -0,0 +1,32 @@
+-- Target SLA per periode untuk satu target (ip_id), satu grup (group_name)
+-- atau semua target jika keduanya NULL. Yang paling spesifik menang.
+
+CREATE TABLE IF NOT EXISTS {{.SLATargets}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NULL,
+    group_name VARCHAR(255) NULL,
+    period VARCHAR(8) NOT NULL,
+    target_percentage DOUBLE NOT NULL,
+    note VARCHAR(255) NULL
+);
+
+-- Pencapaian terhadap target, dihitung ulang setiap rollup periode itu
+-- dihitung ulang. Satu baris per ip_id (group_name NULL) dan per grup yang
+-- punya target sendiri (ip_id NULL).
+CREATE TABLE IF NOT EXISTS {{.SLAAttainment}} (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    period VARCHAR(8) NOT NULL,
+    period_start DATETIME NOT NULL,
+    ip_id INT NULL,
+    group_name VARCHAR(255) NULL,
+    target_percentage DOUBLE NOT NULL,
+    attainment_percentage DOUBLE NULL,
+    budget_minutes DOUBLE NOT NULL,
+    consumed_minutes DOUBLE NOT NULL,
+    remaining_minutes DOUBLE NOT NULL,
+    breached BOOLEAN NOT NULL,
+    complete BOOLEAN NOT NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_period_start (period, period_start),
+    INDEX idx_ip_id (ip_id)
+);
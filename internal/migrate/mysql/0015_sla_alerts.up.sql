@@ -0,0 +1,15 @@
+-- Alert burn rate error budget bulanan. Satu alert per ip_id terbuka
+-- (resolved_at NULL) selama burn rate jendela pendek dan panjang di atas
+-- ambang, lalu ditutup saat salah satunya turun.
+CREATE TABLE IF NOT EXISTS {{.SLAAlerts}} (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    ip_id INT NOT NULL,
+    target_percentage DOUBLE NOT NULL,
+    short_burn_rate DOUBLE NOT NULL,
+    long_burn_rate DOUBLE NOT NULL,
+    threshold DOUBLE NOT NULL,
+    fired_at DATETIME NOT NULL,
+    resolved_at DATETIME NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_ip_resolved (ip_id, resolved_at)
+);
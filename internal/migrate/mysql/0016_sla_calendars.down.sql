@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS {{.SLACalendarHolidays}};
+DROP TABLE IF EXISTS {{.SLACalendars}};
+
+ALTER TABLE {{.IPMonitor}}
+    DROP COLUMN calendar_name;
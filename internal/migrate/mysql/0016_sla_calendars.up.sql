@@ -0,0 +1,22 @@
+-- Kalender jam operasional. Target dengan calendar_name di ip_monitor hanya
+-- dihitung SLA-nya di dalam jam operasional kalender itu, di luar itu (dan
+-- selama hari libur) sampel tidak dihitung. hours contoh
+-- "mon-fri 08:00-17:00; sat 08:00-12:00", timezone nama IANA (Asia/Jakarta).
+
+ALTER TABLE {{.IPMonitor}}
+    ADD COLUMN calendar_name VARCHAR(64) NULL;
+
+CREATE TABLE IF NOT EXISTS {{.SLACalendars}} (
+    name VARCHAR(64) PRIMARY KEY,
+    timezone VARCHAR(64) NOT NULL,
+    hours VARCHAR(255) NOT NULL,
+    note VARCHAR(255) NULL
+);
+
+CREATE TABLE IF NOT EXISTS {{.SLACalendarHolidays}} (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    calendar_name VARCHAR(64) NOT NULL,
+    holiday DATE NOT NULL,
+    note VARCHAR(255) NULL,
+    UNIQUE KEY uniq_calendar_holiday (calendar_name, holiday)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE {{.IncidentState}}
+    DROP COLUMN settled_id,
+    DROP COLUMN checkpoint_id,
+    DROP COLUMN checkpoint_at;
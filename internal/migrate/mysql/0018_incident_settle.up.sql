@@ -0,0 +1,12 @@
+-- Incident builder membaca ulang ping_results di belakang posisi terakhir,
+-- karena id yang lebih kecil bisa baru terlihat setelah id yang lebih besar
+-- (dua penulis commit bersamaan). settled_id: id sampai sini sudah pasti
+-- terlihat semua, pembacaan mulai setelahnya. checkpoint_id: last_id waktu
+-- checkpoint_at, menjadi settled_id setelah incidents.settle_time lewat.
+
+ALTER TABLE {{.IncidentState}}
+    ADD COLUMN settled_id BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN checkpoint_id BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN checkpoint_at DATETIME NULL;
+
+UPDATE {{.IncidentState}} SET settled_id = last_id, checkpoint_id = last_id;
@@ -0,0 +1,7 @@
+UPDATE {{.SummaryUptimeDaily}} SET uptime_percentage = 0 WHERE uptime_percentage IS NULL;
+UPDATE {{.SummaryUptimeWeekly}} SET uptime_percentage = 0 WHERE uptime_percentage IS NULL;
+UPDATE {{.SummaryUptimeMonthly}} SET uptime_percentage = 0 WHERE uptime_percentage IS NULL;
+
+ALTER TABLE {{.SummaryUptimeDaily}} MODIFY uptime_percentage DOUBLE NOT NULL;
+ALTER TABLE {{.SummaryUptimeWeekly}} MODIFY uptime_percentage DOUBLE NOT NULL;
+ALTER TABLE {{.SummaryUptimeMonthly}} MODIFY uptime_percentage DOUBLE NOT NULL;
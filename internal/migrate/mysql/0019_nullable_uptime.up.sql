@@ -0,0 +1,6 @@
+-- uptime_percentage NULL berarti tidak ada waktu yang dihitung (misalnya
+-- periode tanpa sampel dengan missing_data excluded), bukan 0% / down penuh.
+
+ALTER TABLE {{.SummaryUptimeDaily}} MODIFY uptime_percentage DOUBLE NULL;
+ALTER TABLE {{.SummaryUptimeWeekly}} MODIFY uptime_percentage DOUBLE NULL;
+ALTER TABLE {{.SummaryUptimeMonthly}} MODIFY uptime_percentage DOUBLE NULL;
@@ -0,0 +1,4 @@
+-- TTL dari balasan terakhir probe ICMP, NULL untuk baris lama, check
+-- non-ICMP dan probe tanpa balasan.
+
+ALTER TABLE {{.PingResults}} ADD COLUMN ttl INT NULL;
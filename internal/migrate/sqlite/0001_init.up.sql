@@ -0,0 +1,20 @@
+-- Tabel ping_results lokal untuk probe.sink: sqlite, dikirim ke MySQL lewat upload.
+-- Kolom yang belum ada di file SQLite lama ditambahkan oleh migrasi 0002.
+
+CREATE TABLE IF NOT EXISTS {{.PingResults}} (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ip_id INT,
+    timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+    status INT,
+    response_time FLOAT,
+    status_id INT,
+    reason_id INT,
+    packet_loss FLOAT,
+    jitter FLOAT,
+    rtt_min FLOAT,
+    rtt_max FLOAT,
+    rtt_stddev FLOAT,
+    retry_count INT DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_ip_timestamp ON {{.PingResults}} (ip_id, timestamp);
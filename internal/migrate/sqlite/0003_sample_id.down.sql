@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS uniq_sample_id;
+
+ALTER TABLE {{.PingResults}} DROP COLUMN sample_id;
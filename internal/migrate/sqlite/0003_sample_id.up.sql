@@ -0,0 +1,6 @@
+-- sample_id unik per hasil probe, dipakai untuk dedup saat data lokal dikirim ke MySQL.
+-- Baris lama tetap NULL.
+
+ALTER TABLE {{.PingResults}} ADD COLUMN sample_id TEXT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_sample_id ON {{.PingResults}} (sample_id);
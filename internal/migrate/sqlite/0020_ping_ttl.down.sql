@@ -0,0 +1 @@
+ALTER TABLE {{.PingResults}} DROP COLUMN ttl;
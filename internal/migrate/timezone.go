@@ -0,0 +1,203 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"sla_uptime/internal/config"
+)
+
+type timestampColumn struct {
+	table, column string
+}
+
+// Kolom DATETIME yang dulu ditulis slauptime dalam waktu lokal host (DSN
+// loc=Local). Tidak termasuk:
+//   - ping_results.timestamp, yang dulu diisi DEFAULT CURRENT_TIMESTAMP MySQL
+//     (async_mysql) dan zonanya diambil dari migrate.ping_results_timezone
+//   - uptime_summary, yang sejak zlazla/upload_summary sudah ditulis dalam UTC
+//   - waktu yang diisi MySQL sendiri (sla_rules.updated_at, schema_migrations)
+func hostTimeColumns(tables config.TablesConfig) []timestampColumn {
+	return []timestampColumn{
+		{tables.SummaryUptime, "timestamp"},
+		{tables.SummaryDowntime, "timestamp"},
+		{tables.UploadState, "updated_at"},
+		{tables.SummaryRuns, "hour"},
+		{tables.SummaryRuns, "started_at"},
+		{tables.SummaryRuns, "finished_at"},
+		{tables.MaintenanceWindows, "starts_at"},
+		{tables.MaintenanceWindows, "ends_at"},
+		{tables.Incidents, "started_at"},
+		{tables.Incidents, "ended_at"},
+		{tables.Incidents, "last_sample_at"},
+		{tables.Incidents, "updated_at"},
+		{tables.IncidentState, "updated_at"},
+		{tables.IncidentTargets, "last_sample_at"},
+		{tables.IncidentTargets, "updated_at"},
+		{tables.SLAAlerts, "fired_at"},
+		{tables.SLAAlerts, "resolved_at"},
+		{tables.SLAAlerts, "updated_at"},
+	}
+}
+
+// Tabel per hari / minggu / bulan yang kuncinya awal periode lokal. Setelah
+// digeser ke UTC kuncinya tidak lagi di tengah malam UTC, jadi dikosongkan
+// dan dihitung ulang dengan "slauptime rollup", "sla" dan "kpi".
+func periodTables(tables config.TablesConfig) []string {
+	return []string{
+		tables.SummaryUptimeDaily, tables.SummaryUptimeWeekly, tables.SummaryUptimeMonthly,
+		tables.SLAAttainment, tables.SLAKPI,
+	}
+}
+
+// Selisih zona waktu loc terhadap UTC dalam detik. Zona dengan DST tidak punya
+// satu selisih tetap, jadi ditolak: pakai zona tetap yang sesuai data lama,
+// contoh Etc/GMT-7 untuk UTC+7.
+func fixedOffset(loc *time.Location) (int, error) {
+	year := time.Now().Year()
+	_, winter := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	name, summer := time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	if winter != summer {
+		return 0, fmt.Errorf("zona waktu %s (%s) memakai DST, pakai zona waktu tetap data lama, contoh Etc/GMT-7", loc, name)
+	}
+	return summer, nil
+}
+
+// Selisih zona waktu host, tempat slauptime dulu menulis waktu lokal
+func hostOffset() (int, error) {
+	offset, err := fixedOffset(time.Local)
+	if err != nil {
+		return 0, fmt.Errorf("zona waktu host: %w (jalankan ulang dengan TZ, contoh TZ=Etc/GMT-7)", err)
+	}
+	return offset, nil
+}
+
+// Selisih zona waktu ping_results.timestamp lama dari
+// migrate.ping_results_timezone. Tanpa nilai itu migrasi hanya boleh jalan
+// jika ping_results masih kosong, karena zona waktu server MySQL yang dulu
+// mengisi kolom ini tidak bisa ditebak dari host.
+func pingResultsOffset(db *sql.DB, cfg *config.Config) (int, error) {
+	switch tz := cfg.Migrate.PingResultsTimezone; tz {
+	case "":
+		var exists int
+		err := db.QueryRow("SELECT COUNT(*) FROM (SELECT 1 FROM " + cfg.Tables.PingResults + " LIMIT 1) t").Scan(&exists)
+		if err != nil {
+			return 0, fmt.Errorf("gagal membaca %s: %w", cfg.Tables.PingResults, err)
+		}
+		if exists > 0 {
+			return 0, fmt.Errorf("%s sudah berisi data: isi migrate.ping_results_timezone dengan zona waktu server MySQL yang dulu mengisi timestamp (contoh Etc/GMT-7, atau UTC jika tidak perlu digeser), atau %q jika hanya ditulis slauptime probe",
+				cfg.Tables.PingResults, config.HostTimezone)
+		}
+		return 0, nil
+	case config.HostTimezone:
+		return hostOffset()
+	default:
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return 0, fmt.Errorf("migrate.ping_results_timezone tidak dikenal: %q", tz)
+		}
+		offset, err := fixedOffset(loc)
+		if err != nil {
+			return 0, fmt.Errorf("migrate.ping_results_timezone: %w", err)
+		}
+		return offset, nil
+	}
+}
+
+// Geseran satu kolom dalam detik
+type timestampShift struct {
+	timestampColumn
+	seconds int
+}
+
+// Geseran setiap kolom dari waktu lokal ke UTC: kolom yang ditulis slauptime
+// digeser -host, ping_results.timestamp digeser -ping. Untuk down tanda
+// keduanya dibalik oleh pemanggil.
+func timestampShifts(tables config.TablesConfig, host, ping int) []timestampShift {
+	shifts := []timestampShift{{timestampColumn{tables.PingResults, "timestamp"}, -ping}}
+	for _, c := range hostTimeColumns(tables) {
+		shifts = append(shifts, timestampShift{c, -host})
+	}
+	return shifts
+}
+
+// Statement SQL beserta argumennya
+type statement struct {
+	query string
+	args  []any
+}
+
+// Statement yang menggeser setiap kolom lalu mengosongkan tabel periode jika
+// waktu lokal host bukan UTC. Geseran nol dilewati.
+func shiftStatements(tables config.TablesConfig, shifts []timestampShift, host int) []statement {
+	var stmts []statement
+	for _, s := range shifts {
+		if s.seconds == 0 {
+			continue
+		}
+		// Baris digeser berurutan searah geserannya supaya unique key
+		// (ip_id, timestamp) tidak bentrok dengan baris yang belum digeser
+		order := "ASC"
+		if s.seconds > 0 {
+			order = "DESC"
+		}
+		stmts = append(stmts, statement{
+			query: fmt.Sprintf("UPDATE %s SET %s = %s + INTERVAL ? SECOND WHERE %s IS NOT NULL ORDER BY %s %s",
+				s.table, s.column, s.column, s.column, s.column, order),
+			args: []any{s.seconds},
+		})
+	}
+	if host != 0 {
+		for _, table := range periodTables(tables) {
+			stmts = append(stmts, statement{query: "DELETE FROM " + table})
+		}
+	}
+	return stmts
+}
+
+// Menggeser waktu lokal lama menjadi UTC
+func utcTimestampsUp(db *sql.DB, cfg *config.Config) error {
+	return shiftTimestamps(db, cfg, 1)
+}
+
+// Mengembalikan waktu UTC menjadi waktu lokal lama
+func utcTimestampsDown(db *sql.DB, cfg *config.Config) error {
+	return shiftTimestamps(db, cfg, -1)
+}
+
+// direction 1 = lokal ke UTC, -1 = UTC ke lokal
+func shiftTimestamps(db *sql.DB, cfg *config.Config, direction int) error {
+	host, err := hostOffset()
+	if err != nil {
+		return err
+	}
+	ping, err := pingResultsOffset(db, cfg)
+	if err != nil {
+		return err
+	}
+	shifts := timestampShifts(cfg.Tables, direction*host, direction*ping)
+	stmts := shiftStatements(cfg.Tables, shifts, host)
+	if len(stmts) == 0 {
+		return nil // data lama sudah UTC
+	}
+
+	// Satu transaksi, supaya migrasi yang gagal di tengah tidak menggeser
+	// sebagian kolom dua kali saat dijalankan ulang
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, s := range stmts {
+		if _, err := tx.Exec(s.query, s.args...); err != nil {
+			return fmt.Errorf("migrasi zona waktu gagal: %w\n%s", err, s.query)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/store"
+)
+
+func TestTimestampShifts(t *testing.T) {
+	tables := config.Default().Tables
+	shifts := timestampShifts(tables, 7*3600, 5*3600)
+
+	byColumn := make(map[timestampColumn]int)
+	for _, s := range shifts {
+		byColumn[s.timestampColumn] = s.seconds
+	}
+	if got := byColumn[timestampColumn{tables.PingResults, "timestamp"}]; got != -5*3600 {
+		t.Errorf("ping_results.timestamp digeser %d detik, seharusnya %d (zona server)", got, -5*3600)
+	}
+	if got := byColumn[timestampColumn{tables.SummaryUptime, "timestamp"}]; got != -7*3600 {
+		t.Errorf("summary_uptime.timestamp digeser %d detik, seharusnya %d (zona host)", got, -7*3600)
+	}
+	if _, ok := byColumn[timestampColumn{tables.UptimeSummary, "timestamp"}]; ok {
+		t.Errorf("uptime_summary sudah UTC, tidak boleh digeser")
+	}
+}
+
+func TestShiftStatements(t *testing.T) {
+	tables := config.Default().Tables
+
+	tests := []struct {
+		name       string
+		host, ping int
+		updates    int
+		wipe       bool
+	}{
+		{"host dan server UTC", 0, 0, 0, false},
+		{"hanya server bukan UTC", 0, 7 * 3600, 1, false},
+		{"host bukan UTC", 7 * 3600, 0, len(hostTimeColumns(tables)), true},
+		{"keduanya bukan UTC", 7 * 3600, 3600, len(hostTimeColumns(tables)) + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts := shiftStatements(tables, timestampShifts(tables, tt.host, tt.ping), tt.host)
+			var updates, deletes int
+			for _, s := range stmts {
+				switch {
+				case strings.HasPrefix(s.query, "UPDATE"):
+					updates++
+					if strings.Contains(s.query, tables.UptimeSummary+" ") {
+						t.Errorf("uptime_summary ikut digeser: %s", s.query)
+					}
+					// geseran ke belakang (UTC+) harus mulai dari baris paling awal
+					if !strings.HasSuffix(s.query, " ASC") {
+						t.Errorf("geseran negatif harus urut ASC: %s", s.query)
+					}
+				case strings.HasPrefix(s.query, "DELETE"):
+					deletes++
+				}
+			}
+			if updates != tt.updates {
+				t.Errorf("%d UPDATE, seharusnya %d", updates, tt.updates)
+			}
+			if wiped := deletes == len(periodTables(tables)); wiped != tt.wipe || (!tt.wipe && deletes != 0) {
+				t.Errorf("%d tabel periode dikosongkan, seharusnya kosongkan = %v", deletes, tt.wipe)
+			}
+		})
+	}
+}
+
+func TestFixedOffset(t *testing.T) {
+	tests := []struct {
+		zone   string
+		offset int
+		err    bool
+	}{
+		{"UTC", 0, false},
+		{"Etc/GMT-7", 7 * 3600, false},
+		{"Asia/Jakarta", 7 * 3600, false},
+		{"Europe/Amsterdam", 0, true},
+	}
+	for _, tt := range tests {
+		loc, err := time.LoadLocation(tt.zone)
+		if err != nil {
+			t.Skipf("zona %s tidak tersedia: %v", tt.zone, err)
+		}
+		offset, err := fixedOffset(loc)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: error %v, seharusnya error = %v", tt.zone, err, tt.err)
+			continue
+		}
+		if offset != tt.offset {
+			t.Errorf("%s: selisih %d, seharusnya %d", tt.zone, offset, tt.offset)
+		}
+	}
+}
+
+// Migrasi 0017 terhadap MySQL sungguhan. Hanya jalan jika SLA_TEST_MYSQL_DSN
+// berisi database yang boleh diubah isinya.
+func TestUTCTimestampsMySQL(t *testing.T) {
+	dsn := os.Getenv("SLA_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("SLA_TEST_MYSQL_DSN kosong")
+	}
+	cfg := config.Default()
+	cfg.MySQL.DSN = dsn
+	db, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Skema terbaru dulu, dengan host UTC migrasi 0017 tidak menggeser apa pun
+	local := time.Local
+	time.Local = time.UTC
+	cfg.Migrate.PingResultsTimezone = "UTC"
+	if _, err := Up(db, MySQL, &cfg); err != nil {
+		time.Local = local
+		t.Fatal(err)
+	}
+	time.Local = local
+
+	tables := cfg.Tables
+	clear := func() {
+		for _, table := range []string{tables.PingResults, tables.SummaryUptime, tables.UptimeSummary, tables.SummaryUptimeDaily} {
+			if _, err := db.Exec("DELETE FROM " + table + " WHERE ip_id = 990001"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	clear()
+	defer clear()
+
+	at := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	mustExec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.Exec(query, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustExec("INSERT INTO "+tables.PingResults+" (ip_id, timestamp, status) VALUES (990001, ?, '1')", at)
+	mustExec("INSERT INTO "+tables.SummaryUptime+" (ip_id, timestamp) VALUES (990001, ?)", at)
+	mustExec("INSERT INTO "+tables.UptimeSummary+" (ip_id, timestamp) VALUES (990001, ?)", at)
+	mustExec("INSERT INTO "+tables.SummaryUptimeDaily+` (ip_id, period_start, hours, success_count, fail_count, expected_count,
+		unknown_count, up_seconds, down_seconds, unknown_seconds, uptime_percentage, coverage_percentage, updated_at)
+		VALUES (990001, ?, 24, 0, 0, 0, 0, 0, 0, 0, 0, 0, ?)`, at, at)
+
+	read := func(table string) time.Time {
+		t.Helper()
+		var ts time.Time
+		if err := db.QueryRow("SELECT timestamp FROM " + table + " WHERE ip_id = 990001").Scan(&ts); err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	// host UTC+7, server MySQL lama UTC+5
+	time.Local = time.FixedZone("UTC+7", 7*3600)
+	defer func() { time.Local = local }()
+	cfg.Migrate.PingResultsTimezone = "Etc/GMT-5"
+
+	if err := utcTimestampsUp(db, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(tables.PingResults); !got.Equal(at.Add(-5 * time.Hour)) {
+		t.Errorf("ping_results: %s, seharusnya %s", got, at.Add(-5*time.Hour))
+	}
+	if got := read(tables.SummaryUptime); !got.Equal(at.Add(-7 * time.Hour)) {
+		t.Errorf("summary_uptime: %s, seharusnya %s", got, at.Add(-7*time.Hour))
+	}
+	if got := read(tables.UptimeSummary); !got.Equal(at) {
+		t.Errorf("uptime_summary ikut digeser: %s", got)
+	}
+	var daily int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + tables.SummaryUptimeDaily + " WHERE ip_id = 990001").Scan(&daily); err != nil {
+		t.Fatal(err)
+	}
+	if daily != 0 {
+		t.Errorf("%s tidak dikosongkan", tables.SummaryUptimeDaily)
+	}
+
+	if err := utcTimestampsDown(db, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{tables.PingResults, tables.SummaryUptime, tables.UptimeSummary} {
+		if got := read(table); !got.Equal(at) {
+			t.Errorf("%s setelah down: %s, seharusnya %s", table, got, at)
+		}
+	}
+}
+
+func TestPingResultsOffsetRequiresTimezone(t *testing.T) {
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "ping.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	cfg := config.Default()
+	if _, err := Up(db, SQLite, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// ping_results kosong: tidak ada yang perlu digeser
+	if offset, err := pingResultsOffset(db, &cfg); err != nil || offset != 0 {
+		t.Fatalf("tabel kosong: selisih %d error %v, seharusnya 0 tanpa error", offset, err)
+	}
+
+	if _, err := db.Exec("INSERT INTO " + cfg.Tables.PingResults + " (ip_id, status) VALUES (1, 1)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pingResultsOffset(db, &cfg); err == nil {
+		t.Errorf("ping_results berisi data tanpa migrate.ping_results_timezone seharusnya ditolak")
+	}
+
+	local := time.Local
+	time.Local = time.FixedZone("UTC+7", 7*3600)
+	defer func() { time.Local = local }()
+
+	tests := []struct {
+		zone   string
+		offset int
+		err    bool
+	}{
+		{"UTC", 0, false},
+		{"Etc/GMT-5", 5 * 3600, false},
+		{config.HostTimezone, 7 * 3600, false},
+		{"Europe/Amsterdam", 0, true},
+	}
+	for _, tt := range tests {
+		cfg.Migrate.PingResultsTimezone = tt.zone
+		offset, err := pingResultsOffset(db, &cfg)
+		if (err != nil) != tt.err || offset != tt.offset {
+			t.Errorf("%s: selisih %d error %v, seharusnya %d error = %v", tt.zone, offset, err, tt.offset, tt.err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"sla_uptime/internal/config"
+)
+
+// Migrasi yang ditulis di Go karena butuh cek kondisi dulu
+var goMigrations = map[Dialect][]Migration{
+	MySQL: {
+		{Version: 2, Name: "upgrade_existing", Up: upgradeExistingMySQL, Down: noop},
+		{Version: 17, Name: "utc_timestamps", Up: utcTimestampsUp, Down: utcTimestampsDown},
+	},
+	SQLite: {
+		{Version: 2, Name: "upgrade_existing", Up: upgradeExistingSQLite, Down: noop},
+	},
+}
+
+// Langkah down untuk migrasi yang hanya melengkapi database lama ke bentuk
+// migrasi sebelumnya, tidak ada yang perlu dikembalikan
+func noop(*sql.DB, *config.Config) error {
+	return nil
+}
+
+// Kolom yang dulu ditambahkan async_mysql saat start. Database yang dibuat
+// sebelum ada migrasi mungkin belum punya sebagian kolom ini.
+func probeColumns(tables config.TablesConfig) []struct{ table, column, definition string } {
+	return []struct{ table, column, definition string }{
+		{tables.PingResults, "packet_loss", "FLOAT"},
+		{tables.PingResults, "jitter", "FLOAT"},
+		{tables.PingResults, "rtt_min", "FLOAT"},
+		{tables.PingResults, "rtt_max", "FLOAT"},
+		{tables.PingResults, "rtt_stddev", "FLOAT"},
+		{tables.PingResults, "retry_count", "INT DEFAULT 0"},
+		{tables.IPMonitor, "check_type", "VARCHAR(16) NOT NULL DEFAULT 'icmp'"},
+		{tables.IPMonitor, "check_params", "TEXT NULL"},
+	}
+}
+
+// Menyamakan database lama dengan skema 0001: kolom probe dan unique key
+// (ip_id, timestamp) yang dibutuhkan ON DUPLICATE KEY UPDATE di summary
+func upgradeExistingMySQL(db *sql.DB, cfg *config.Config) error {
+	tables := cfg.Tables
+	for _, c := range probeColumns(tables) {
+		if err := ensureColumnMySQL(db, c.table, c.column, c.definition); err != nil {
+			return fmt.Errorf("gagal menambahkan kolom %s.%s: %w", c.table, c.column, err)
+		}
+	}
+
+	for _, table := range []string{tables.SummaryUptime, tables.SummaryDowntime} {
+		if err := ensureUniqueKeyMySQL(db, table, "uniq_ip_timestamp", "ip_id", "timestamp"); err != nil {
+			return fmt.Errorf("gagal menambahkan unique key (ip_id, timestamp) di %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// File SQLite lama dari zlazla/async belum punya kolom statistik paket
+func upgradeExistingSQLite(db *sql.DB, cfg *config.Config) error {
+	tables := cfg.Tables
+	for _, c := range probeColumns(tables) {
+		if c.table != tables.PingResults {
+			continue
+		}
+		if err := ensureColumnSQLite(db, c.table, c.column, c.definition); err != nil {
+			return fmt.Errorf("gagal menambahkan kolom %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
+// MySQL tidak punya ADD COLUMN IF NOT EXISTS, jadi cek dulu lewat information_schema
+func ensureColumnMySQL(db *sql.DB, table, column, definition string) error {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE()
+		  AND TABLE_NAME = ?
+		  AND COLUMN_NAME = ?
+	`, table, column).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// Menambahkan unique key jika belum ada unique index dengan kolom yang sama persis
+func ensureUniqueKeyMySQL(db *sql.DB, table, name string, columns ...string) error {
+	rows, err := db.Query(`
+		SELECT INDEX_NAME, GROUP_CONCAT(COLUMN_NAME ORDER BY SEQ_IN_INDEX)
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE()
+		  AND TABLE_NAME = ?
+		  AND NON_UNIQUE = 0
+		GROUP BY INDEX_NAME
+	`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	want := strings.Join(columns, ",")
+	found := false
+	for rows.Next() {
+		var indexName, indexColumns string
+		if err := rows.Scan(&indexName, &indexColumns); err != nil {
+			return err
+		}
+		found = found || indexColumns == want
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	if found {
+		return nil
+	}
+
+	// Gagal jika tabel masih punya baris duplikat, hapus duplikatnya dulu lalu jalankan ulang
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD UNIQUE KEY %s (%s)", table, name, want))
+	return err
+}
+
+func ensureColumnSQLite(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		found = found || name == column
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	if found {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
@@ -0,0 +1,307 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Jenis pengecekan yang bisa dipakai di kolom ip_monitor.check_type
+const (
+	checkTypeICMP = "icmp"
+	checkTypeTCP  = "tcp"
+	checkTypeHTTP = "http"
+	checkTypeDNS  = "dns"
+)
+
+// New membuat prober sesuai check_type dan check_params (JSON) dari ip_monitor.
+// check_type kosong dianggap icmp supaya data lama tetap jalan.
+func New(checkType, checkParams string, icmp Prober, opts Options) (Prober, error) {
+	switch strings.ToLower(strings.TrimSpace(checkType)) {
+	case "", checkTypeICMP:
+		return icmp, nil
+	case checkTypeTCP:
+		var p tcpProber
+		if err := decodeCheckParams(checkParams, &p); err != nil {
+			return nil, err
+		}
+		if p.Port <= 0 || p.Port > 65535 {
+			return nil, fmt.Errorf("check_params tcp: port tidak valid: %d", p.Port)
+		}
+		p.timeout = opts.Timeout
+		return burstProber{&p, opts}, nil
+	case checkTypeHTTP:
+		var p httpProber
+		if err := decodeCheckParams(checkParams, &p); err != nil {
+			return nil, err
+		}
+		if err := p.init(opts.Timeout); err != nil {
+			return nil, err
+		}
+		return burstProber{&p, opts}, nil
+	case checkTypeDNS:
+		var p dnsProber
+		if err := decodeCheckParams(checkParams, &p); err != nil {
+			return nil, err
+		}
+		if err := p.init(opts.Timeout); err != nil {
+			return nil, err
+		}
+		return burstProber{&p, opts}, nil
+	default:
+		return nil, fmt.Errorf("check_type tidak dikenal: %q", checkType)
+	}
+}
+
+func decodeCheckParams(raw string, v interface{}) error {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return fmt.Errorf("check_params bukan JSON yang valid: %w", err)
+	}
+	return nil
+}
+
+// Satu kali percobaan untuk cek non-ICMP, ok=false berarti gagal
+type attempter interface {
+	attempt(ip string) (rtt time.Duration, ok bool)
+}
+
+// Menjalankan beberapa percobaan berturut-turut seperti paket ping,
+// supaya cek tcp/http/dns juga punya packet loss dan jitter
+type burstProber struct {
+	attempter
+	opts Options
+}
+
+func (p burstProber) Probe(ip string) Result {
+	var rtts []time.Duration
+	for i := 0; i < p.opts.Count; i++ {
+		if i > 0 {
+			time.Sleep(p.opts.Interval)
+		}
+		if rtt, ok := p.attempt(ip); ok {
+			rtts = append(rtts, rtt)
+		}
+	}
+	return summarizeRTTs(p.opts.Count, rtts)
+}
+
+// Cek TCP connect ke port tertentu, contoh check_params: {"port": 443}
+type tcpProber struct {
+	Port int `json:"port"`
+
+	timeout time.Duration
+}
+
+func (p *tcpProber) attempt(ip string) (time.Duration, bool) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(p.Port)), p.timeout)
+	if err != nil {
+		return 0, false
+	}
+	rtt := time.Since(start)
+	conn.Close()
+
+	return rtt, true
+}
+
+// Cek HTTP(S), contoh check_params:
+// {"url": "https://{ip}/health", "expect_status": 200, "body_match": "OK"}
+// url default http://{ip}/, expect_status 0 berarti status 2xx/3xx dianggap up
+type httpProber struct {
+	URL          string `json:"url"`
+	Method       string `json:"method"`
+	ExpectStatus int    `json:"expect_status"`
+	BodyMatch    string `json:"body_match"`
+	Insecure     bool   `json:"insecure"`
+
+	bodyRegexp *regexp.Regexp
+	client     *http.Client
+}
+
+func (p *httpProber) init(timeout time.Duration) error {
+	if p.URL == "" {
+		p.URL = "http://{ip}/"
+	}
+	if p.Method == "" {
+		p.Method = http.MethodGet
+	}
+	if p.BodyMatch != "" {
+		re, err := regexp.Compile(p.BodyMatch)
+		if err != nil {
+			return fmt.Errorf("check_params http: body_match tidak valid: %w", err)
+		}
+		p.bodyRegexp = re
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	if p.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	p.client = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// Redirect tidak diikuti supaya expect_status 3xx bisa dicek
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return nil
+}
+
+func (p *httpProber) attempt(ip string) (time.Duration, bool) {
+	host := ip
+	if strings.Contains(ip, ":") {
+		host = "[" + ip + "]"
+	}
+	req, err := http.NewRequest(p.Method, strings.ReplaceAll(p.URL, "{ip}", host), nil)
+	if err != nil {
+		return 0, false
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, false
+	}
+	defer resp.Body.Close()
+
+	if p.ExpectStatus != 0 && resp.StatusCode != p.ExpectStatus {
+		return 0, false
+	}
+	if p.ExpectStatus == 0 && resp.StatusCode >= 400 {
+		return 0, false
+	}
+
+	if p.bodyRegexp != nil {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil || !p.bodyRegexp.Match(body) {
+			return 0, false
+		}
+	}
+	rtt := time.Since(start)
+
+	return rtt, true
+}
+
+// Cek DNS dengan IP target sebagai DNS server, contoh check_params:
+// {"name": "example.com", "type": "A", "expect": "93.184.216.34"}
+type dnsProber struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Port   int    `json:"port"`
+	Expect string `json:"expect"`
+
+	timeout time.Duration
+}
+
+func (p *dnsProber) init(timeout time.Duration) error {
+	if p.Name == "" {
+		return fmt.Errorf("check_params dns: name wajib diisi")
+	}
+	p.Type = strings.ToUpper(p.Type)
+	if p.Type == "" {
+		p.Type = "A"
+	}
+	switch p.Type {
+	case "A", "AAAA", "CNAME", "MX", "NS", "TXT":
+	default:
+		return fmt.Errorf("check_params dns: type tidak didukung: %q", p.Type)
+	}
+	if p.Port == 0 {
+		p.Port = 53
+	}
+	p.timeout = timeout
+	return nil
+}
+
+func (p *dnsProber) attempt(ip string) (time.Duration, bool) {
+	server := net.JoinHostPort(ip, strconv.Itoa(p.Port))
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	answers, err := p.lookup(ctx, resolver)
+	if err != nil || len(answers) == 0 {
+		return 0, false
+	}
+	rtt := time.Since(start)
+
+	if p.Expect != "" && !containsFold(answers, p.Expect) {
+		return 0, false
+	}
+
+	return rtt, true
+}
+
+func (p *dnsProber) lookup(ctx context.Context, r *net.Resolver) ([]string, error) {
+	var answers []string
+	switch p.Type {
+	case "A", "AAAA":
+		network := "ip4"
+		if p.Type == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := r.LookupIP(ctx, network, p.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case "CNAME":
+		cname, err := r.LookupCNAME(ctx, p.Name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "MX":
+		mxs, err := r.LookupMX(ctx, p.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			answers = append(answers, mx.Host)
+		}
+	case "NS":
+		nss, err := r.LookupNS(ctx, p.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			answers = append(answers, ns.Host)
+		}
+	case "TXT":
+		return r.LookupTXT(ctx, p.Name)
+	}
+	return answers, nil
+}
+
+func containsFold(values []string, want string) bool {
+	want = strings.TrimSuffix(want, ".")
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSuffix(v, "."), want) {
+			return true
+		}
+	}
+	return false
+}
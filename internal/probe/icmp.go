@@ -0,0 +1,118 @@
+package probe
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/go-ping/ping"
+)
+
+// Mode ping yang didukung
+const (
+	pingModeAuto         = "auto"         // privileged di Windows, unprivileged di OS lain
+	pingModePrivileged   = "privileged"   // raw socket ICMP, butuh root / CAP_NET_RAW
+	pingModeUnprivileged = "unprivileged" // UDP-ICMP, butuh net.ipv4.ping_group_range di Linux
+	pingModeExec         = "exec"         // fallback: panggil binary ping dan parsing output
+)
+
+// Fungsi ping, dipakai sebagai Prober untuk cek ICMP
+type pingFunc func(ip string) Result
+
+func (f pingFunc) Probe(ip string) Result {
+	return f(ip)
+}
+
+// NewICMP membuat prober ICMP sesuai mode yang dipilih
+func NewICMP(mode string, opts Options) (Prober, error) {
+	if mode == pingModeAuto {
+		mode = pingModeUnprivileged
+		if runtime.GOOS == "windows" {
+			mode = pingModePrivileged
+		}
+	}
+
+	switch mode {
+	case pingModePrivileged:
+		return pingFunc(func(ip string) Result { return pingNative(ip, true, opts) }), nil
+	case pingModeUnprivileged:
+		return pingFunc(func(ip string) Result { return pingNative(ip, false, opts) }), nil
+	case pingModeExec:
+		return pingFunc(pingExec), nil
+	default:
+		return nil, fmt.Errorf("mode ping tidak dikenal: %q", mode)
+	}
+}
+
+// Ping langsung dari proses ini tanpa menjalankan binary ping
+func pingNative(ip string, privileged bool, opts Options) Result {
+	pinger, err := ping.NewPinger(ip)
+	if err != nil {
+		return downResult()
+	}
+	pinger.SetLogger(ping.NoopLogger{})
+	pinger.SetPrivileged(privileged)
+	pinger.Count = opts.Count
+	pinger.Interval = opts.Interval
+	pinger.Timeout = time.Duration(opts.Count-1)*opts.Interval + opts.Timeout
+
+	var ttl int
+	pinger.OnRecv = func(pkt *ping.Packet) {
+		ttl = pkt.Ttl
+	}
+
+	if err := pinger.Run(); err != nil {
+		return downResult()
+	}
+
+	stats := pinger.Statistics()
+	result := summarizeRTTs(opts.Count, stats.Rtts)
+	result.TTL = ttl
+	return result
+}
+
+var (
+	windowsTimeRegexp = regexp.MustCompile(`time[=<]\s*(\d+)ms`)
+	windowsTTLRegexp  = regexp.MustCompile(`TTL=(\d+)`)
+	unixRTTRegexp     = regexp.MustCompile(`min/avg/max/(?:stddev|mdev) = [\d.]+/([\d.]+)/`)
+	unixTTLRegexp     = regexp.MustCompile(`ttl=(\d+)`)
+)
+
+// Fallback lama: menjalankan binary ping dan membaca output-nya.
+// Selalu satu paket, pengaturan jumlah paket tidak berlaku di mode ini.
+func pingExec(ip string) Result {
+	isWindows := runtime.GOOS == "windows"
+
+	var cmd *exec.Cmd
+	if isWindows {
+		cmd = exec.Command("ping", "-n", "1", "-w", "1000", ip)
+	} else {
+		cmd = exec.Command("ping", "-c", "1", "-W", "1", ip)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return downResult()
+	}
+
+	result := Result{Status: "1"}
+	outputStr := string(output)
+
+	timeRegexp, ttlRegexp := unixRTTRegexp, unixTTLRegexp
+	if isWindows {
+		timeRegexp, ttlRegexp = windowsTimeRegexp, windowsTTLRegexp
+	}
+
+	if matches := timeRegexp.FindStringSubmatch(outputStr); len(matches) > 1 {
+		result.ResponseTime, _ = strconv.ParseFloat(matches[1], 64)
+		result.MinRTT, result.MaxRTT = result.ResponseTime, result.ResponseTime
+	}
+	if matches := ttlRegexp.FindStringSubmatch(outputStr); len(matches) > 1 {
+		result.TTL, _ = strconv.Atoi(matches[1])
+	}
+
+	return result
+}
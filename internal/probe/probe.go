@@ -0,0 +1,104 @@
+// Package probe berisi semua jenis pengecekan target (icmp, tcp, http, dns)
+// dan aturan kapan hasil pengecekan dianggap up atau down.
+package probe
+
+import (
+	"math"
+	"time"
+)
+
+// Prober melakukan satu kali pengecekan ke sebuah IP dan hasilnya
+// disimpan ke ping_results dengan bentuk yang sama untuk semua jenis cek
+type Prober interface {
+	Probe(ip string) Result
+}
+
+// Hasil satu kali probe (bisa terdiri dari beberapa paket)
+type Result struct {
+	Status       string  // "1" jika dianggap up, "0" jika tidak
+	ResponseTime float64 // rata-rata RTT dalam milidetik
+	TTL          int     // TTL dari balasan terakhir, 0 jika tidak ada balasan
+	PacketLoss   float64 // persentase paket yang hilang (0-100)
+	Jitter       float64 // rata-rata selisih RTT antar paket berurutan (ms)
+	MinRTT       float64
+	MaxRTT       float64
+	StdDevRTT    float64
+	Retries      int // jumlah probe ulang sebelum hasil ini dicatat
+}
+
+// Pengaturan jumlah paket per probe
+type Options struct {
+	Timeout  time.Duration // batas waktu menunggu balasan paket terakhir
+	Count    int           // jumlah paket per probe
+	Interval time.Duration // jeda antar paket dalam satu probe
+}
+
+// Menghitung loss, jitter dan min/avg/max/stddev dari RTT paket yang dibalas
+func summarizeRTTs(sent int, rtts []time.Duration) Result {
+	if sent <= 0 || len(rtts) == 0 {
+		return downResult()
+	}
+	if len(rtts) > sent {
+		rtts = rtts[:sent]
+	}
+
+	result := Result{
+		Status:     "1",
+		PacketLoss: float64(sent-len(rtts)) / float64(sent) * 100,
+		MinRTT:     milliseconds(rtts[0]),
+		MaxRTT:     milliseconds(rtts[0]),
+	}
+
+	var sum, jitterSum float64
+	for i, rtt := range rtts {
+		ms := milliseconds(rtt)
+		sum += ms
+		result.MinRTT = math.Min(result.MinRTT, ms)
+		result.MaxRTT = math.Max(result.MaxRTT, ms)
+		if i > 0 {
+			jitterSum += math.Abs(ms - milliseconds(rtts[i-1]))
+		}
+	}
+	result.ResponseTime = sum / float64(len(rtts))
+	if len(rtts) > 1 {
+		result.Jitter = jitterSum / float64(len(rtts)-1)
+	}
+
+	var variance float64
+	for _, rtt := range rtts {
+		diff := milliseconds(rtt) - result.ResponseTime
+		variance += diff * diff
+	}
+	result.StdDevRTT = math.Sqrt(variance / float64(len(rtts)))
+
+	return result
+}
+
+// Confirm melakukan probe ulang dengan cepat sebelum hasil down dicatat, supaya
+// paket yang hilang sesaat tidak langsung jadi downtime. Berhenti di probe
+// pertama yang up.
+func Confirm(p Prober, ip string, maxLoss float64, retries int, interval time.Duration) Result {
+	result := ApplyLossRule(p.Probe(ip), maxLoss)
+	for attempt := 1; result.Status != "1" && attempt <= retries; attempt++ {
+		time.Sleep(interval)
+		result = ApplyLossRule(p.Probe(ip), maxLoss)
+		result.Retries = attempt
+	}
+	return result
+}
+
+// ApplyLossRule menandai probe sebagai up selama packet loss tidak melebihi maxLoss (%)
+func ApplyLossRule(result Result, maxLoss float64) Result {
+	if result.Status == "1" && result.PacketLoss > maxLoss {
+		result.Status = "0"
+	}
+	return result
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func downResult() Result {
+	return Result{Status: "0", PacketLoss: 100}
+}
@@ -0,0 +1,99 @@
+package probe
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSummarizeRTTs(t *testing.T) {
+	ms := func(values ...float64) []time.Duration {
+		rtts := make([]time.Duration, len(values))
+		for i, v := range values {
+			rtts[i] = time.Duration(v * float64(time.Millisecond))
+		}
+		return rtts
+	}
+	tests := []struct {
+		name string
+		sent int
+		rtts []time.Duration
+		want Result
+	}{
+		{"tanpa balasan", 3, nil, Result{Status: "0", PacketLoss: 100}},
+		{"tidak ada paket terkirim", 0, ms(10), Result{Status: "0", PacketLoss: 100}},
+		{"satu balasan", 1, ms(10), Result{Status: "1", ResponseTime: 10, MinRTT: 10, MaxRTT: 10}},
+		{"satu dari empat hilang", 4, ms(10, 20, 30),
+			Result{Status: "1", PacketLoss: 25, ResponseTime: 20, MinRTT: 10, MaxRTT: 30, Jitter: 10, StdDevRTT: math.Sqrt(200.0 / 3)}},
+		{"jitter dari selisih berurutan", 4, ms(10, 30, 20, 40),
+			Result{Status: "1", ResponseTime: 25, MinRTT: 10, MaxRTT: 40, Jitter: 50.0 / 3, StdDevRTT: math.Sqrt(125)}},
+		{"balasan duplikat tidak dihitung", 2, ms(10, 20, 99),
+			Result{Status: "1", ResponseTime: 15, MinRTT: 10, MaxRTT: 20, Jitter: 10, StdDevRTT: 5}},
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	for _, tt := range tests {
+		got := summarizeRTTs(tt.sent, tt.rtts)
+		if got.Status != tt.want.Status || !near(got.PacketLoss, tt.want.PacketLoss) || !near(got.ResponseTime, tt.want.ResponseTime) ||
+			!near(got.MinRTT, tt.want.MinRTT) || !near(got.MaxRTT, tt.want.MaxRTT) ||
+			!near(got.Jitter, tt.want.Jitter) || !near(got.StdDevRTT, tt.want.StdDevRTT) {
+			t.Errorf("%s: %+v, seharusnya %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyLossRule(t *testing.T) {
+	tests := []struct {
+		status  string
+		loss    float64
+		maxLoss float64
+		want    string
+	}{
+		{"1", 0, 50, "1"},
+		{"1", 49.9, 50, "1"},
+		{"1", 50, 50, "1"}, // batas masih dianggap up
+		{"1", 50.1, 50, "0"},
+		{"1", 100, 50, "0"},
+		{"1", 0, 0, "1"},
+		{"1", 25, 0, "0"},
+		{"0", 0, 50, "0"}, // yang sudah down tidak jadi up
+	}
+	for _, tt := range tests {
+		got := ApplyLossRule(Result{Status: tt.status, PacketLoss: tt.loss}, tt.maxLoss)
+		if got.Status != tt.want {
+			t.Errorf("ApplyLossRule(status %s, loss %v, max %v) = %s, seharusnya %s", tt.status, tt.loss, tt.maxLoss, got.Status, tt.want)
+		}
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	up := Result{Status: "1"}
+	down := downResult()
+	lossy := Result{Status: "1", PacketLoss: 75}
+	tests := []struct {
+		name    string
+		results []Result // hasil probe berturut-turut
+		retries int
+		status  string
+		calls   int
+		retried int // retry_count yang dicatat
+	}{
+		{"langsung up", []Result{up}, 3, "1", 1, 0},
+		{"up setelah dua probe ulang", []Result{down, down, up}, 3, "1", 3, 2},
+		{"tetap down", []Result{down, down, down, up}, 2, "0", 3, 2},
+		{"tanpa probe ulang", []Result{down, up}, 0, "0", 1, 0},
+		{"loss di atas max_loss diulang", []Result{lossy, up}, 1, "1", 2, 1},
+	}
+	for _, tt := range tests {
+		calls := 0
+		p := pingFunc(func(string) Result {
+			r := tt.results[calls]
+			calls++
+			return r
+		})
+		got := Confirm(p, "10.0.0.1", 50, tt.retries, 0)
+		if got.Status != tt.status || calls != tt.calls || got.Retries != tt.retried {
+			t.Errorf("%s: status %s, %d probe, retry_count %d, seharusnya %s, %d, %d",
+				tt.name, got.Status, calls, got.Retries, tt.status, tt.calls, tt.retried)
+		}
+	}
+}
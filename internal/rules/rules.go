@@ -0,0 +1,275 @@
+// Package rules mengklasifikasikan sampel berdasarkan kombinasi status_id dan
+// reason_id: dihitung ke SLA, downtime terjadwal, atau tidak dihitung.
+// Aturannya dibaca dari tabel sla_rules, atau dari filters di konfigurasi jika
+// tabel itu kosong, lalu diterapkan di Go oleh summarize dan upload bersama
+// jendela maintenance.
+package rules
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/maintenance"
+)
+
+// Class adalah klasifikasi satu sampel
+type Class string
+
+const (
+	SLA       Class = "sla"       // dihitung ke SLA (summary_uptime)
+	Scheduled Class = "scheduled" // downtime terjadwal (summary_downtime)
+	Excluded  Class = "excluded"  // tidak dihitung sama sekali
+)
+
+func (c Class) valid() bool {
+	return c == SLA || c == Scheduled || c == Excluded
+}
+
+// Rule memetakan satu kombinasi status_id / reason_id ke klasifikasi.
+// StatusID atau ReasonID yang tidak Valid (NULL) berarti semua nilai.
+type Rule struct {
+	StatusID sql.NullInt64
+	ReasonID sql.NullInt64
+	Class    Class
+	Note     string
+}
+
+func (r Rule) String() string {
+	status, reason := "*", "*"
+	if r.StatusID.Valid {
+		status = fmt.Sprint(r.StatusID.Int64)
+	}
+	if r.ReasonID.Valid {
+		reason = fmt.Sprint(r.ReasonID.Int64)
+	}
+	return fmt.Sprintf("status_id=%s reason_id=%s -> %s", status, reason, r.Class)
+}
+
+type key struct {
+	status, reason sql.NullInt64
+}
+
+func newKey(status, reason sql.NullInt64) key {
+	// Int64 dinolkan supaya NULL selalu menghasilkan key yang sama
+	if !status.Valid {
+		status.Int64 = 0
+	}
+	if !reason.Valid {
+		reason.Int64 = 0
+	}
+	return key{status, reason}
+}
+
+// Set adalah kumpulan aturan yang siap dipakai
+type Set struct {
+	rules  []Rule
+	byKey  map[key]Class
+	Source string // asal aturan, untuk ditampilkan
+
+	// status_id yang sampelnya dengan reason_id NULL excluded, bukan ikut
+	// aturan status_id saja. Hanya diisi dari filters, lihat fromFilters.
+	nullReason map[int64]bool
+}
+
+// New membuat Set dan menolak klasifikasi tidak dikenal atau kombinasi yang
+// muncul lebih dari sekali
+func New(list []Rule, source string) (*Set, error) {
+	s := &Set{byKey: make(map[key]Class, len(list)), Source: source}
+	for _, r := range list {
+		if !r.Class.valid() {
+			return nil, fmt.Errorf("aturan %s: klasifikasi %q tidak dikenal (sla, scheduled, excluded)", r, r.Class)
+		}
+		k := newKey(r.StatusID, r.ReasonID)
+		if _, dup := s.byKey[k]; dup {
+			return nil, fmt.Errorf("aturan %s: kombinasi status_id / reason_id sudah ada", r)
+		}
+		s.byKey[k] = r.Class
+		s.rules = append(s.rules, r)
+	}
+	sort.SliceStable(s.rules, func(i, j int) bool { return specificity(s.rules[i]) > specificity(s.rules[j]) })
+	return s, nil
+}
+
+func specificity(r Rule) int {
+	n := 0
+	if r.StatusID.Valid {
+		n += 2
+	}
+	if r.ReasonID.Valid {
+		n++
+	}
+	return n
+}
+
+// Rules mengembalikan semua aturan, dari yang paling spesifik
+func (s *Set) Rules() []Rule {
+	return s.rules
+}
+
+// NullReasonExcluded mengembalikan status_id (urut) yang sampelnya dengan
+// reason_id NULL tidak dihitung, bukan ikut aturan status_id saja
+func (s *Set) NullReasonExcluded() []int64 {
+	list := make([]int64, 0, len(s.nullReason))
+	for status := range s.nullReason {
+		list = append(list, status)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+// Classify mengembalikan klasifikasi sampel. Aturan yang paling spesifik
+// menang: status_id + reason_id, lalu status_id saja, lalu reason_id saja,
+// lalu aturan tanpa keduanya. Sampel yang tidak cocok dengan aturan manapun
+// tidak dihitung. status_id / reason_id sampel yang NULL hanya cocok dengan
+// aturan yang berlaku untuk semua nilai, kecuali status_id sampel itu ada di
+// NullReasonExcluded.
+func (s *Set) Classify(statusID, reasonID sql.NullInt64) Class {
+	if statusID.Valid && !reasonID.Valid {
+		if s.nullReason[statusID.Int64] {
+			return Excluded
+		}
+	}
+	all := sql.NullInt64{}
+	for _, k := range []key{
+		newKey(statusID, reasonID),
+		newKey(statusID, all),
+		newKey(all, reasonID),
+		newKey(all, all),
+	} {
+		if c, ok := s.byKey[k]; ok {
+			return c
+		}
+	}
+	return Excluded
+}
+
+// ClassifyAt seperti Classify, tapi sampel sla milik ipID yang jatuh di dalam
+// jendela maintenance schedule (boleh nil) menjadi scheduled
+func (s *Set) ClassifyAt(schedule *maintenance.Schedule, ipID int, at time.Time, statusID, reasonID sql.NullInt64) Class {
+	class := s.Classify(statusID, reasonID)
+	if class == SLA && schedule.Active(ipID, at) {
+		return Scheduled
+	}
+	return class
+}
+
+// Filter memilih sampel dengan klasifikasi tertentu
+type Filter struct {
+	Set         *Set
+	Class       Class
+	Maintenance *maintenance.Schedule // nil = tanpa jendela maintenance
+}
+
+// Match mengembalikan true jika sampel ipID pada waktu at masuk klasifikasi
+// filter. Sampel sla di dalam jendela maintenance target itu dihitung sebagai
+// scheduled; sampel yang excluded tetap excluded.
+func (f Filter) Match(ipID int, at time.Time, statusID, reasonID sql.NullInt64) bool {
+	return f.Set.ClassifyAt(f.Maintenance, ipID, at, statusID, reasonID) == f.Class
+}
+
+// MatchStatus hanya melihat status_id / reason_id, tanpa jendela maintenance
+func (f Filter) MatchStatus(statusID, reasonID sql.NullInt64) bool {
+	return f.Set.Classify(statusID, reasonID) == f.Class
+}
+
+// MayMatch mengembalikan true jika sampel dengan status_id / reason_id ini di
+// dalam [from, to) mungkin masuk klasifikasi filter, dipakai untuk mencari jam
+// tanpa membaca setiap sampel
+func (f Filter) MayMatch(from, to time.Time, statusID, reasonID sql.NullInt64) bool {
+	class := f.Set.Classify(statusID, reasonID)
+	if class == f.Class {
+		return true
+	}
+	return class == SLA && f.Class == Scheduled && f.Maintenance.Overlaps(from, to)
+}
+
+// FromFilters menerjemahkan filters.uptime / filters.downtime di konfigurasi
+// menjadi aturan: setiap status_ids masuk sla / scheduled, kecuali
+// exclude_reason_ids-nya. Jika status_id yang sama ada di keduanya, uptime menang.
+// Sampel dengan reason_id NULL tidak tertangkap aturan ini, lihat fromFilters.
+func FromFilters(filters config.FiltersConfig) []Rule {
+	var list []Rule
+	seen := make(map[key]bool)
+	add := func(status, reason sql.NullInt64, class Class) {
+		k := newKey(status, reason)
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		list = append(list, Rule{StatusID: status, ReasonID: reason, Class: class})
+	}
+	for _, f := range []struct {
+		rule  config.FilterRule
+		class Class
+	}{
+		{filters.Uptime, SLA},
+		{filters.Downtime, Scheduled},
+	} {
+		for _, status := range f.rule.StatusIDs {
+			s := sql.NullInt64{Int64: int64(status), Valid: true}
+			for _, reason := range f.rule.ExcludeReasonIDs {
+				add(s, sql.NullInt64{Int64: int64(reason), Valid: true}, Excluded)
+			}
+			add(s, sql.NullInt64{}, f.class)
+		}
+	}
+	return list
+}
+
+// fromFilters membuat Set dari FromFilters dengan perilaku NULL query lama:
+// `reason_id != 16` tidak cocok dengan reason_id NULL, jadi sampel status_id
+// yang punya exclude_reason_ids dan reason_id-nya NULL tidak dihitung
+func fromFilters(filters config.FiltersConfig) (*Set, error) {
+	set, err := New(FromFilters(filters), "konfigurasi filters")
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range []config.FilterRule{filters.Uptime, filters.Downtime} {
+		if len(rule.ExcludeReasonIDs) == 0 {
+			continue
+		}
+		if set.nullReason == nil {
+			set.nullReason = make(map[int64]bool)
+		}
+		for _, status := range rule.StatusIDs {
+			set.nullReason[int64(status)] = true
+		}
+	}
+	return set, nil
+}
+
+// Load membaca aturan dari tabel sla_rules. Jika tabel masih kosong, aturan
+// diambil dari filters di konfigurasi supaya perilaku lama tetap sama.
+func Load(db *sql.DB, table string, filters config.FiltersConfig) (*Set, error) {
+	rows, err := db.Query("SELECT status_id, reason_id, class, COALESCE(note, '') FROM " + table)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca aturan dari %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var list []Rule
+	for rows.Next() {
+		var r Rule
+		var class string
+		if err := rows.Scan(&r.StatusID, &r.ReasonID, &class, &r.Note); err != nil {
+			return nil, fmt.Errorf("gagal membaca aturan dari %s: %w", table, err)
+		}
+		r.Class = Class(class)
+		list = append(list, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		return fromFilters(filters)
+	}
+	set, err := New(list, "tabel "+table)
+	if err != nil {
+		return nil, fmt.Errorf("aturan di %s tidak valid: %w", table, err)
+	}
+	return set, nil
+}
@@ -0,0 +1,124 @@
+package rules
+
+import (
+	"database/sql"
+	"testing"
+
+	"sla_uptime/internal/config"
+)
+
+func id(v int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: v, Valid: true}
+}
+
+var null = sql.NullInt64{}
+
+func TestClassifyPrecedence(t *testing.T) {
+	set, err := New([]Rule{
+		{StatusID: null, ReasonID: null, Class: Scheduled},
+		{StatusID: null, ReasonID: id(16), Class: Excluded},
+		{StatusID: id(7), ReasonID: null, Class: SLA},
+		{StatusID: id(7), ReasonID: id(16), Class: Scheduled},
+		{StatusID: id(9), ReasonID: id(3), Class: SLA},
+	}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		status, reason sql.NullInt64
+		want           Class
+	}{
+		{"status dan reason", id(7), id(16), Scheduled},
+		{"status saja", id(7), id(1), SLA},
+		{"reason saja", id(8), id(16), Excluded},
+		{"tanpa keduanya", id(8), id(1), Scheduled},
+		{"status_id NULL", null, id(1), Scheduled},
+		{"reason_id NULL ikut aturan status saja", id(7), null, SLA},
+		{"reason_id NULL tanpa aturan status", id(9), null, Scheduled},
+		{"status dan reason lebih kuat dari reason saja", id(9), id(3), SLA},
+	}
+	for _, tt := range tests {
+		if got := set.Classify(tt.status, tt.reason); got != tt.want {
+			t.Errorf("%s: Classify(%v, %v) = %s, seharusnya %s", tt.name, tt.status, tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyNoMatch(t *testing.T) {
+	set, err := New([]Rule{{StatusID: id(7), ReasonID: null, Class: SLA}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := set.Classify(id(8), id(1)); got != Excluded {
+		t.Errorf("sampel tanpa aturan = %s, seharusnya excluded", got)
+	}
+}
+
+func TestNewRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		list []Rule
+	}{
+		{"klasifikasi tidak dikenal", []Rule{{StatusID: id(7), Class: "up"}}},
+		{"kombinasi ganda", []Rule{{StatusID: id(7), Class: SLA}, {StatusID: id(7), Class: Excluded}}},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.list, "test"); err == nil {
+			t.Errorf("%s: seharusnya ditolak", tt.name)
+		}
+	}
+}
+
+// Aturan dari filters default harus sama dengan query lama
+// `status_id = 7 AND reason_id != 16` / `status_id = 8 AND reason_id != 16`
+func TestFromFiltersMatchesLegacyQuery(t *testing.T) {
+	set, err := fromFilters(config.Default().Filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		status, reason sql.NullInt64
+		want           Class
+	}{
+		{id(7), id(1), SLA},
+		{id(7), id(16), Excluded},
+		{id(7), null, Excluded},
+		{id(8), id(1), Scheduled},
+		{id(8), id(16), Excluded},
+		{id(8), null, Excluded},
+		{id(9), id(1), Excluded},
+		{null, null, Excluded},
+	}
+	for _, tt := range tests {
+		if got := set.Classify(tt.status, tt.reason); got != tt.want {
+			t.Errorf("Classify(%v, %v) = %s, seharusnya %s", tt.status, tt.reason, got, tt.want)
+		}
+	}
+	if got := set.NullReasonExcluded(); len(got) != 2 || got[0] != 7 || got[1] != 8 {
+		t.Errorf("NullReasonExcluded = %v, seharusnya [7 8]", got)
+	}
+}
+
+// Tanpa exclude_reason_ids query lama tidak punya syarat reason_id, jadi
+// reason_id NULL tetap dihitung
+func TestFromFiltersWithoutExclusions(t *testing.T) {
+	set, err := fromFilters(config.FiltersConfig{
+		Uptime:   config.FilterRule{StatusIDs: []int{7}},
+		Downtime: config.FilterRule{StatusIDs: []int{7, 8}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := set.Classify(id(7), null); got != SLA {
+		t.Errorf("status 7 reason NULL = %s, seharusnya sla (uptime menang)", got)
+	}
+	if got := set.Classify(id(8), null); got != Scheduled {
+		t.Errorf("status 8 reason NULL = %s, seharusnya scheduled", got)
+	}
+	if got := set.NullReasonExcluded(); len(got) != 0 {
+		t.Errorf("NullReasonExcluded = %v, seharusnya kosong", got)
+	}
+}
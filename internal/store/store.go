@@ -0,0 +1,58 @@
+// Package store berisi koneksi database yang dipakai bersama oleh semua subcommand.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"sla_uptime/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// OpenMySQL membuka koneksi MySQL, mengatur pool, lalu memastikan server bisa dihubungi.
+//
+// Semua DATETIME disimpan dan dibaca sebagai UTC apa pun zona waktu host dan
+// server: loc di DSN dipaksa UTC (waktu dari Go dikirim dan dibaca dalam UTC)
+// dan time_zone sesi juga UTC supaya NOW() / CURRENT_TIMESTAMP sama.
+func OpenMySQL(cfg config.MySQLConfig) (*sql.DB, error) {
+	dsn, err := mysql.ParseDSN(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("mysql.dsn tidak valid: %w", err)
+	}
+	dsn.Loc = time.UTC
+	if dsn.Params == nil {
+		dsn.Params = make(map[string]string)
+	}
+	dsn.Params["time_zone"] = "'+00:00'"
+
+	db, err := sql.Open("mysql", dsn.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka koneksi MySQL: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("gagal melakukan ping ke database MySQL: %w", err)
+	}
+	return db, nil
+}
+
+// OpenSQLite membuka database SQLite lokal
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka database SQLite: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("gagal membuka database SQLite %s: %w", path, err)
+	}
+	return db, nil
+}
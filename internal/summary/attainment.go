@@ -0,0 +1,310 @@
+package summary
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/calendar"
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/target"
+)
+
+// Attainment adalah pencapaian SLA satu target (IPID diisi) atau satu grup
+// (Group diisi) terhadap targetnya dalam satu periode
+type Attainment struct {
+	IPID   int
+	Group  string
+	Target float64 // persen
+
+	Attainment sql.NullFloat64 // uptime persen, NULL jika belum ada waktu yang terukur
+	Budget     time.Duration   // downtime yang boleh terjadi sepanjang periode
+	Consumed   time.Duration   // downtime yang sudah terjadi
+	Remaining  time.Duration   // Budget - Consumed, negatif jika sudah lewat
+	Breached   bool
+	Complete   bool // periode sudah selesai
+}
+
+// Scope menandai satu baris pencapaian: target (IPID) atau grup (Group)
+type Scope struct {
+	IPID  int
+	Group string
+}
+
+// Scope mengembalikan target atau grup pemilik baris a
+func (a Attainment) Scope() Scope {
+	if a.Group != "" {
+		return Scope{Group: a.Group}
+	}
+	return Scope{IPID: a.IPID}
+}
+
+// Targets adalah target SLA satu periode dari tabel sla_targets
+type Targets struct {
+	byIP    map[int]float64
+	byGroup map[string]float64
+	all     sql.NullFloat64
+}
+
+// Lookup mengembalikan target (persen) yang berlaku untuk ipID di grup group:
+// per ip_id, lalu per grup, lalu untuk semua target
+func (t Targets) Lookup(ipID int, group string) (float64, bool) {
+	if v, ok := t.byIP[ipID]; ok {
+		return v, true
+	}
+	if v, ok := t.byGroup[group]; ok && group != "" {
+		return v, true
+	}
+	return t.all.Float64, t.all.Valid
+}
+
+// Empty mengembalikan true jika tidak ada target sama sekali
+func (t Targets) Empty() bool {
+	return len(t.byIP) == 0 && len(t.byGroup) == 0 && !t.all.Valid
+}
+
+// NewTargets mengembalikan target SLA per ip_id, per grup, dan untuk semua
+// target (all, tidak Valid jika tidak ada)
+func NewTargets(byIP map[int]float64, byGroup map[string]float64, all sql.NullFloat64) Targets {
+	if byIP == nil {
+		byIP = make(map[int]float64)
+	}
+	if byGroup == nil {
+		byGroup = make(map[string]float64)
+	}
+	return Targets{byIP: byIP, byGroup: byGroup, all: all}
+}
+
+// LoadTargets membaca target SLA untuk period dari tabel sla_targets
+func LoadTargets(db *sql.DB, table string, period Period) (Targets, error) {
+	t := NewTargets(nil, nil, sql.NullFloat64{})
+	rows, err := db.Query("SELECT ip_id, group_name, target_percentage FROM "+table+" WHERE period = ?", string(period))
+	if err != nil {
+		return t, fmt.Errorf("gagal membaca %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ipID sql.NullInt64
+		var group sql.NullString
+		var pct float64
+		if err := rows.Scan(&ipID, &group, &pct); err != nil {
+			return t, fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		switch {
+		case ipID.Valid:
+			t.byIP[int(ipID.Int64)] = pct
+		case group.Valid:
+			t.byGroup[group.String] = pct
+		default:
+			t.all = sql.NullFloat64{Float64: pct, Valid: true}
+		}
+	}
+	return t, rows.Err()
+}
+
+// Usage adalah waktu up/down/unknown (detik) satu target di rollup periode,
+// dan lama jam operasional sepanjang periode penuh
+type Usage struct {
+	Up, Down, Unknown float64
+	Length            time.Duration
+	Measured          bool // ada baris rollup
+}
+
+func (u *Usage) add(v Usage) {
+	u.Up += v.Up
+	u.Down += v.Down
+	u.Unknown += v.Unknown
+	u.Length += v.Length
+	u.Measured = u.Measured || v.Measured
+}
+
+// ComputeAttainment membandingkan rollup periode yang dimulai di start dengan
+// target di sla_targets, lihat EvaluateAttainment.
+//
+// Target di ip_monitor yang punya target SLA tapi tidak punya baris rollup
+// (misalnya prober mati sepanjang periode) tetap dapat baris dengan pencapaian
+// NULL: seluruh jam operasional yang sudah lewat dianggap unknown, jadi
+// budget terpakai habis jika missing_data = down dan utuh jika tidak. Panjang
+// periode untuk budget adalah jam operasional sepanjang periode untuk target
+// yang punya kalender.
+func ComputeAttainment(db *sql.DB, tables config.TablesConfig, period Period, start, now time.Time, policy string) ([]Attainment, error) {
+	targets, err := LoadTargets(db, tables.SLATargets, period)
+	if err != nil {
+		return nil, err
+	}
+	if targets.Empty() {
+		return nil, nil
+	}
+	ids, err := target.IDs(db, tables.IPMonitor)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := target.Groups(db, tables.IPMonitor)
+	if err != nil {
+		return nil, err
+	}
+	calendars, err := calendar.Load(db, tables)
+	if err != nil {
+		return nil, err
+	}
+	end := period.Next(start)
+	elapsed := end
+	if now.Before(end) {
+		elapsed = now
+	}
+
+	table := period.Table(tables)
+	rows, err := db.Query("SELECT ip_id, up_seconds, down_seconds, unknown_seconds FROM "+table+" WHERE period_start = ?", start)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	usage := make(map[int]Usage)
+	for rows.Next() {
+		var ipID int
+		u := Usage{Measured: true}
+		if err := rows.Scan(&ipID, &u.Up, &u.Down, &u.Unknown); err != nil {
+			return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		u.Length = calendars.For(ipID).Covered(start, end)
+		usage[ipID] = u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, ipID := range ids {
+		if _, ok := usage[ipID]; !ok {
+			cal := calendars.For(ipID)
+			usage[ipID] = Usage{Unknown: cal.Covered(start, elapsed).Seconds(), Length: cal.Covered(start, end)}
+		}
+	}
+	return EvaluateAttainment(targets, usage, groups, !now.Before(end), policy), nil
+}
+
+// EvaluateAttainment menghitung pencapaian setiap target di usage yang punya
+// target SLA, urut ip_id, lalu setiap grup yang punya target sendiri (jumlah
+// usage anggotanya), urut nama. complete menandai periode yang sudah selesai.
+//
+// Error budget = (100 - target)% dari Length. Downtime yang terpakai adalah
+// Down, ditambah Unknown jika missing_data = down. Breach jika budget sudah
+// habis, atau jika periode sudah selesai dan pencapaian di bawah target.
+func EvaluateAttainment(targets Targets, usage map[int]Usage, groups map[int]string, complete bool, policy string) []Attainment {
+	byGroup := make(map[string]Usage)
+	for ipID, u := range usage {
+		if group, ok := groups[ipID]; ok {
+			g := byGroup[group]
+			g.add(u)
+			byGroup[group] = g
+		}
+	}
+
+	evaluate := func(u Usage, pct float64) Attainment {
+		a := Attainment{Target: pct, Complete: complete}
+		consumed := u.Down
+		if policy == MissingDown {
+			consumed += u.Unknown
+		}
+		if u.Measured {
+			a.Attainment = Uptime(u.Up, u.Down, u.Unknown, policy)
+		}
+		a.Budget = time.Duration((100 - pct) / 100 * float64(u.Length))
+		a.Consumed = time.Duration(consumed * float64(time.Second))
+		a.Remaining = a.Budget - a.Consumed
+		a.Breached = a.Remaining < 0 || (complete && a.Attainment.Valid && a.Attainment.Float64 < pct)
+		return a
+	}
+
+	var result []Attainment
+	for ipID, u := range usage {
+		pct, ok := targets.Lookup(ipID, groups[ipID])
+		if !ok {
+			continue
+		}
+		a := evaluate(u, pct)
+		a.IPID = ipID
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].IPID < result[j].IPID })
+
+	names := make([]string, 0, len(byGroup))
+	for name := range byGroup {
+		if _, ok := targets.byGroup[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a := evaluate(byGroup[name], targets.byGroup[name])
+		a.Group = name
+		result = append(result, a)
+	}
+	return result
+}
+
+// Breached mengembalikan target dan grup yang sudah tercatat breach untuk
+// periode itu di sla_attainment, sebelum StoreAttainment menggantinya
+func Breached(db *sql.DB, table string, period Period, start time.Time) (map[Scope]bool, error) {
+	rows, err := db.Query("SELECT ip_id, group_name FROM "+table+" WHERE period = ? AND period_start = ? AND breached", string(period), start)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	breached := make(map[Scope]bool)
+	for rows.Next() {
+		var ipID sql.NullInt64
+		var group sql.NullString
+		if err := rows.Scan(&ipID, &group); err != nil {
+			return nil, fmt.Errorf("gagal membaca %s: %w", table, err)
+		}
+		breached[Scope{IPID: int(ipID.Int64), Group: group.String}] = true
+	}
+	return breached, rows.Err()
+}
+
+// StoreAttainment mengganti pencapaian periode itu di sla_attainment dalam satu transaksi
+func StoreAttainment(db *sql.DB, table string, period Period, start time.Time, rows []Attainment) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM "+table+" WHERE period = ? AND period_start = ?", string(period), start); err != nil {
+		return fmt.Errorf("gagal menghapus pencapaian lama di %s: %w", table, err)
+	}
+
+	stmt, err := tx.Prepare(`
+        INSERT INTO ` + table + ` (period, period_start, ip_id, group_name, target_percentage, attainment_percentage,
+                                   budget_minutes, consumed_minutes, remaining_minutes, breached, complete, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `)
+	if err != nil {
+		return fmt.Errorf("gagal mempersiapkan insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, a := range rows {
+		var ipID sql.NullInt64
+		var group sql.NullString
+		if a.Group != "" {
+			group = sql.NullString{String: a.Group, Valid: true}
+		} else {
+			ipID = sql.NullInt64{Int64: int64(a.IPID), Valid: true}
+		}
+		_, err := stmt.Exec(string(period), start, ipID, group, a.Target, a.Attainment,
+			a.Budget.Minutes(), a.Consumed.Minutes(), a.Remaining.Minutes(), a.Breached, a.Complete, now)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan pencapaian ke %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package summary_test
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/summary"
+	"sla_uptime/internal/testdb"
+)
+
+type attainmentWant struct {
+	target     float64
+	attainment float64 // NaN = NULL
+	consumed   float64 // detik
+	breached   bool
+}
+
+func checkAttainment(t *testing.T, name string, rows []summary.Attainment, n int, want map[summary.Scope]attainmentWant) {
+	t.Helper()
+	got := make(map[summary.Scope]summary.Attainment)
+	for _, a := range rows {
+		got[a.Scope()] = a
+	}
+	if len(got) != n {
+		t.Errorf("%s: %d baris, seharusnya %d", name, len(got), n)
+	}
+	for scope, w := range want {
+		a, ok := got[scope]
+		if !ok {
+			t.Errorf("%s: tidak ada baris untuk %+v", name, scope)
+			continue
+		}
+		attainmentOK := a.Attainment.Valid && math.Abs(a.Attainment.Float64-w.attainment) < 1e-9
+		if math.IsNaN(w.attainment) {
+			attainmentOK = !a.Attainment.Valid
+		}
+		if a.Target != w.target || !attainmentOK || a.Consumed != time.Duration(w.consumed*float64(time.Second)) || a.Breached != w.breached {
+			t.Errorf("%s: %+v = target %v pencapaian %+v terpakai %s breach %v, seharusnya %v %v %vs %v",
+				name, scope, a.Target, a.Attainment, a.Consumed, a.Breached, w.target, w.attainment, w.consumed, w.breached)
+		}
+	}
+}
+
+func TestEvaluateAttainment(t *testing.T) {
+	const day = 24 * time.Hour
+	usage := map[int]summary.Usage{
+		1: {Up: 86340, Down: 60, Length: day, Measured: true},       // target grup core 99.9%, budget 86.4 detik
+		2: {Up: 86000, Down: 400, Length: day, Measured: true},      // target sendiri 99.5%, budget 432 detik
+		3: {Unknown: 86400, Length: day},                            // tanpa rollup
+		4: {Up: 43200, Unknown: 43200, Length: day, Measured: true}, // target semua 99%, separuh hari unknown
+		5: {Up: 84600, Down: 1800, Length: day, Measured: true},     // 97.9%, budget 864 detik sudah lewat
+		6: {Up: 3000, Down: 600, Length: day, Measured: true},       // di bawah target, budget masih sisa
+		7: {Up: 3600, Length: 8 * time.Hour, Measured: true},        // kalender 8 jam, budget 288 detik
+	}
+	groups := map[int]string{1: "core", 2: "core", 3: "core", 6: "edge"}
+	targets := summary.NewTargets(map[int]float64{2: 99.5}, map[string]float64{"core": 99.9}, sql.NullFloat64{Float64: 99, Valid: true})
+
+	null := math.NaN()
+	tests := []struct {
+		name     string
+		targets  summary.Targets
+		policy   string
+		complete bool
+		rows     int
+		want     map[summary.Scope]attainmentWant
+	}{
+		{"excluded, periode selesai", targets, summary.MissingExcluded, true, 8, map[summary.Scope]attainmentWant{
+			{IPID: 1}:       {99.9, 86340.0 / 86400 * 100, 60, false},
+			{IPID: 2}:       {99.5, 86000.0 / 86400 * 100, 400, false},
+			{IPID: 3}:       {99.9, null, 0, false},
+			{IPID: 4}:       {99.0, 100, 0, false},
+			{IPID: 5}:       {99.0, 84600.0 / 86400 * 100, 1800, true},
+			{IPID: 6}:       {99.0, 3000.0 / 3600 * 100, 600, true},
+			{IPID: 7}:       {99.0, 100, 0, false},
+			{Group: "core"}: {99.9, 172340.0 / 172800 * 100, 460, true},
+		}},
+		{"excluded, periode berjalan", targets, summary.MissingExcluded, false, 8, map[summary.Scope]attainmentWant{
+			{IPID: 5}: {99.0, 84600.0 / 86400 * 100, 1800, true}, // budget sudah habis
+			{IPID: 6}: {99.0, 3000.0 / 3600 * 100, 600, false},   // masih bisa tercapai
+		}},
+		{"down, periode selesai", targets, summary.MissingDown, true, 8, map[summary.Scope]attainmentWant{
+			{IPID: 1}:       {99.9, 86340.0 / 86400 * 100, 60, false},
+			{IPID: 3}:       {99.9, null, 86400, true},
+			{IPID: 4}:       {99.0, 50, 43200, true},
+			{Group: "core"}: {99.9, 172340.0 / 259200 * 100, 460 + 86400, true},
+		}},
+		{"up, periode selesai", targets, summary.MissingUp, true, 8, map[summary.Scope]attainmentWant{
+			{IPID: 3}: {99.9, null, 0, false},
+			{IPID: 4}: {99.0, 100, 0, false},
+		}},
+		// tanpa target semua: hanya ip_id 2 dan anggota grup core, grup
+		// edge tidak punya target sendiri
+		{"tanpa target semua", summary.NewTargets(map[int]float64{2: 99.5}, map[string]float64{"core": 99.9}, sql.NullFloat64{}),
+			summary.MissingExcluded, true, 4, map[summary.Scope]attainmentWant{
+				{IPID: 1}:       {99.9, 86340.0 / 86400 * 100, 60, false},
+				{IPID: 2}:       {99.5, 86000.0 / 86400 * 100, 400, false},
+				{IPID: 3}:       {99.9, null, 0, false},
+				{Group: "core"}: {99.9, 172340.0 / 172800 * 100, 460, true},
+			}},
+	}
+	for _, tt := range tests {
+		rows := summary.EvaluateAttainment(tt.targets, usage, groups, tt.complete, tt.policy)
+		checkAttainment(t, tt.name, rows, tt.rows, tt.want)
+		for _, a := range rows {
+			if a.Complete != tt.complete {
+				t.Errorf("%s: %+v complete %v, seharusnya %v", tt.name, a.Scope(), a.Complete, tt.complete)
+			}
+		}
+	}
+
+	// Budget dari Length: (100 - target)% dari jam operasional, dijumlahkan untuk grup
+	for _, a := range summary.EvaluateAttainment(targets, usage, groups, true, summary.MissingExcluded) {
+		length := day
+		switch {
+		case a.Group == "core":
+			length *= 3
+		case a.IPID == 7:
+			length = 8 * time.Hour
+		}
+		want := time.Duration((100 - a.Target) / 100 * float64(length))
+		if a.Budget != want || a.Remaining != a.Budget-a.Consumed {
+			t.Errorf("%+v: budget %s sisa %s, seharusnya %s dan %s", a.Scope(), a.Budget, a.Remaining, want, want-a.Consumed)
+		}
+	}
+}
+
+// Perhitungannya di TestEvaluateAttainment, di sini hanya pembacaan target,
+// rollup dan target tanpa rollup dari database, lalu penyimpanannya
+func TestComputeAttainment(t *testing.T) {
+	db, cfg := testdb.MySQL(t)
+	tables := cfg.Tables
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	mustExec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.Exec(query, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustExec("INSERT INTO " + tables.IPMonitor + ` (id, ip, status_id, reason_id, group_name) VALUES
+		(1, '10.0.0.1', 7, 1, 'core'), (2, '10.0.0.2', 7, 1, 'core'), (3, '10.0.0.3', 7, 1, 'core'),
+		(4, '10.0.0.4', 7, 1, NULL), (5, '10.0.0.5', 7, 1, NULL)`)
+	mustExec("INSERT INTO " + tables.SLATargets + ` (ip_id, group_name, period, target_percentage) VALUES
+		(NULL, NULL, 'daily', 99.0), (NULL, 'core', 'daily', 99.9), (2, NULL, 'daily', 99.5), (NULL, NULL, 'monthly', 50)`)
+	rollup := func(ipID int, start time.Time, up, down, unknown float64) {
+		t.Helper()
+		mustExec("INSERT INTO "+tables.SummaryUptimeDaily+` (ip_id, period_start, hours, success_count, fail_count,
+			expected_count, unknown_count, up_seconds, down_seconds, unknown_seconds, uptime_percentage, coverage_percentage, updated_at)
+			VALUES (?, ?, 24, 0, 0, 0, 0, ?, ?, ?, 0, 0, ?)`, ipID, start, up, down, unknown, start)
+	}
+	rollup(1, day, 86340, 60, 0)
+	rollup(2, day, 86000, 400, 0)
+	rollup(4, day, 43200, 0, 43200)
+	rollup(5, day, 84600, 1800, 0)
+	rollup(5, day.AddDate(0, 0, 1), 0, 86400, 0) // periode lain
+	// ip_id 3 tidak punya rollup sama sekali
+
+	null := math.NaN()
+	rows, err := summary.ComputeAttainment(db, tables, summary.Daily, day, day.AddDate(0, 0, 2), summary.MissingExcluded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAttainment(t, "periode selesai", rows, 6, map[summary.Scope]attainmentWant{
+		{IPID: 1}:       {99.9, 86340.0 / 86400 * 100, 60, false},
+		{IPID: 2}:       {99.5, 86000.0 / 86400 * 100, 400, false},
+		{IPID: 3}:       {99.9, null, 0, false},
+		{IPID: 4}:       {99.0, 100, 0, false},
+		{IPID: 5}:       {99.0, 84600.0 / 86400 * 100, 1800, true},
+		{Group: "core"}: {99.9, 172340.0 / 172800 * 100, 460, true},
+	})
+	// Target tanpa rollup: jam yang sudah lewat dianggap unknown
+	running, err := summary.ComputeAttainment(db, tables, summary.Daily, day, day.Add(6*time.Hour), summary.MissingDown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAttainment(t, "periode berjalan", running, 6, map[summary.Scope]attainmentWant{
+		{IPID: 3}: {99.9, null, 6 * 3600, true},
+	})
+
+	// Breached membaca breach yang sudah tersimpan
+	if err := summary.StoreAttainment(db, tables.SLAAttainment, summary.Daily, day, rows); err != nil {
+		t.Fatal(err)
+	}
+	breached, err := summary.Breached(db, tables.SLAAttainment, summary.Daily, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(breached) != 2 || !breached[summary.Scope{IPID: 5}] || !breached[summary.Scope{Group: "core"}] {
+		t.Errorf("Breached = %v, seharusnya ip_id 5 dan grup core", breached)
+	}
+	var stored sql.NullFloat64
+	if err := db.QueryRow("SELECT attainment_percentage FROM " + tables.SLAAttainment + " WHERE ip_id = 3").Scan(&stored); err != nil || stored.Valid {
+		t.Errorf("pencapaian ip_id 3 tersimpan %+v (%v), seharusnya NULL", stored, err)
+	}
+}
+
+func TestComputeAttainmentWithoutTargets(t *testing.T) {
+	db, cfg := testdb.MySQL(t)
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := db.Exec("INSERT INTO " + cfg.Tables.IPMonitor + " (id, ip) VALUES (1, '10.0.0.1')"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := summary.ComputeAttainment(db, cfg.Tables, summary.Weekly, day, day, summary.MissingDown)
+	if err != nil || len(rows) != 0 {
+		t.Errorf("tanpa target: %d baris (%v), seharusnya tidak ada", len(rows), err)
+	}
+}
@@ -0,0 +1,119 @@
+package summary
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/calendar"
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/rules"
+)
+
+// Kebijakan untuk sampel yang seharusnya ada tapi tidak tercatat (summary.missing_data)
+const (
+	MissingExcluded = "excluded" // tidak dihitung, uptime hanya dari waktu yang tertutup sampel
+	MissingUp       = "up"       // dihitung sebagai up
+	MissingDown     = "down"     // dihitung sebagai down
+)
+
+// ExpectedSamples mengembalikan jumlah sampel yang seharusnya ada dalam window
+// jika probe berjalan setiap interval
+func ExpectedSamples(window, interval time.Duration) int {
+	if interval <= 0 {
+		return 0
+	}
+	return int(window / interval)
+}
+
+// ApplyCoverage mengisi ExpectedCount dan UnknownCount (jumlah sampel),
+// UnknownSeconds dan CoveragePercentage (waktu) setiap Row untuk rentang
+// [from, to), lalu menghitung UptimePercentage dari waktu up/down sesuai
+// kebijakan policy. Untuk target yang punya kalender hanya jam operasional
+// di rentang itu yang dihitung; Row target yang sama sekali di luar jam
+// operasional dibuang.
+func ApplyCoverage(rows []Row, from, to time.Time, interval time.Duration, policy string, calendars *calendar.Set) []Row {
+	result := rows[:0]
+	for _, r := range rows {
+		window := calendars.For(r.IPID).Covered(from, to)
+		if window <= 0 {
+			continue
+		}
+		expected := ExpectedSamples(window, interval)
+		measured := r.SuccessCount + r.FailCount
+
+		r.ExpectedCount = expected
+		r.UnknownCount = 0
+		if expected > measured {
+			r.UnknownCount = expected - measured
+		}
+
+		covered := r.UpSeconds + r.DownSeconds
+		r.UnknownSeconds = math.Max(0, window.Seconds()-covered)
+		r.CoveragePercentage = math.Min(100, covered/window.Seconds()*100)
+
+		r.UptimePercentage = Uptime(r.UpSeconds, r.DownSeconds, r.UnknownSeconds, policy)
+		result = append(result, r)
+	}
+	return result
+}
+
+// Uptime menghitung persentase uptime dari lama up, down dan unknown (detik)
+// sesuai kebijakan missing_data, dipakai juga untuk menjumlahkan beberapa jam
+// di report. Hasilnya tidak Valid (NULL) jika tidak ada waktu yang dihitung,
+// misalnya jam tanpa sampel dengan missing_data excluded, supaya tidak
+// terbaca sebagai 0% (down penuh).
+func Uptime(up, down, unknown float64, policy string) sql.NullFloat64 {
+	total := up + down
+	switch policy {
+	case MissingUp:
+		up += unknown
+		total += unknown
+	case MissingDown:
+		total += unknown
+	}
+	if total == 0 {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: up / total * 100, Valid: true}
+}
+
+// AddSilentTargets menambahkan Row kosong untuk target di ip_monitor yang
+// status_id / reason_id-nya lolos filter tapi tidak punya sampel sama sekali di
+// [from, to), misalnya karena prober mati sepanjang jam. Target yang belum
+// pernah di-probe sebelum to (baru ditambahkan) tidak ikut, supaya backfill jam
+// lama tidak terisi unknown.
+func AddSilentTargets(db *sql.DB, tables config.TablesConfig, filter rules.Filter, rows []Row, from, to time.Time) ([]Row, error) {
+	result, err := db.Query(`
+        SELECT m.id, m.status_id, m.reason_id
+        FROM `+tables.IPMonitor+` m
+        WHERE EXISTS (SELECT 1 FROM `+tables.PingResults+` p WHERE p.ip_id = m.id AND p.timestamp < ?)
+    `, to)
+	if err != nil {
+		return rows, fmt.Errorf("gagal membaca target dari %s: %w", tables.IPMonitor, err)
+	}
+	defer result.Close()
+
+	seen := make(map[int]bool, len(rows))
+	for _, r := range rows {
+		seen[r.IPID] = true
+	}
+	for result.Next() {
+		var id int
+		var statusID, reasonID sql.NullInt64
+		if err := result.Scan(&id, &statusID, &reasonID); err != nil {
+			return rows, fmt.Errorf("gagal membaca target dari %s: %w", tables.IPMonitor, err)
+		}
+		if !seen[id] && filter.MatchStatus(statusID, reasonID) {
+			rows = append(rows, Row{IPID: id, Timestamp: from})
+		}
+	}
+	if err := result.Err(); err != nil {
+		return rows, err
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].IPID < rows[j].IPID })
+	return rows, nil
+}
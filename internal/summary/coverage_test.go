@@ -0,0 +1,93 @@
+package summary_test
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/summary"
+)
+
+func TestUptime(t *testing.T) {
+	valid := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	tests := []struct {
+		name              string
+		up, down, unknown float64
+		policy            string
+		want              sql.NullFloat64
+	}{
+		{"excluded mengabaikan unknown", 90, 10, 100, summary.MissingExcluded, valid(90)},
+		{"up menghitung unknown sebagai up", 90, 10, 100, summary.MissingUp, valid(95)},
+		{"down menghitung unknown sebagai down", 90, 10, 100, summary.MissingDown, valid(45)},
+		{"excluded tanpa waktu terukur", 0, 0, 3600, summary.MissingExcluded, sql.NullFloat64{}},
+		{"up tanpa waktu terukur", 0, 0, 3600, summary.MissingUp, valid(100)},
+		{"down tanpa waktu terukur", 0, 0, 3600, summary.MissingDown, valid(0)},
+		{"tidak ada waktu sama sekali", 0, 0, 0, summary.MissingDown, sql.NullFloat64{}},
+		{"down penuh", 0, 3600, 0, summary.MissingExcluded, valid(0)},
+	}
+	for _, tt := range tests {
+		got := summary.Uptime(tt.up, tt.down, tt.unknown, tt.policy)
+		if got.Valid != tt.want.Valid || math.Abs(got.Float64-tt.want.Float64) > 1e-9 {
+			t.Errorf("%s: Uptime = %+v, seharusnya %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyCoverage(t *testing.T) {
+	from := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	tests := []struct {
+		name                 string
+		row                  summary.Row
+		policy               string
+		expected, unknown    int
+		unknownSec, coverage float64
+		uptime               sql.NullFloat64
+	}{
+		{"lengkap", summary.Row{SuccessCount: 60, UpSeconds: 3600}, summary.MissingExcluded,
+			60, 0, 0, 100, sql.NullFloat64{Float64: 100, Valid: true}},
+		{"separuh jam tanpa sampel", summary.Row{SuccessCount: 20, FailCount: 10, UpSeconds: 1200, DownSeconds: 600}, summary.MissingExcluded,
+			60, 30, 1800, 50, sql.NullFloat64{Float64: 1200.0 / 1800 * 100, Valid: true}},
+		{"separuh jam tanpa sampel, missing down", summary.Row{SuccessCount: 20, FailCount: 10, UpSeconds: 1200, DownSeconds: 600}, summary.MissingDown,
+			60, 30, 1800, 50, sql.NullFloat64{Float64: 1200.0 / 3600 * 100, Valid: true}},
+		{"target diam", summary.Row{}, summary.MissingExcluded,
+			60, 60, 3600, 0, sql.NullFloat64{}},
+		{"sampel lebih banyak dari seharusnya", summary.Row{SuccessCount: 70, UpSeconds: 3600}, summary.MissingExcluded,
+			60, 0, 0, 100, sql.NullFloat64{Float64: 100, Valid: true}},
+	}
+	for _, tt := range tests {
+		rows := summary.ApplyCoverage([]summary.Row{tt.row}, from, to, time.Minute, tt.policy, nil)
+		if len(rows) != 1 {
+			t.Fatalf("%s: %d baris, seharusnya 1", tt.name, len(rows))
+		}
+		r := rows[0]
+		if r.ExpectedCount != tt.expected || r.UnknownCount != tt.unknown {
+			t.Errorf("%s: expected %d unknown %d, seharusnya %d dan %d", tt.name, r.ExpectedCount, r.UnknownCount, tt.expected, tt.unknown)
+		}
+		if r.UnknownSeconds != tt.unknownSec || math.Abs(r.CoveragePercentage-tt.coverage) > 1e-9 {
+			t.Errorf("%s: unknown %v detik coverage %v%%, seharusnya %v dan %v", tt.name, r.UnknownSeconds, r.CoveragePercentage, tt.unknownSec, tt.coverage)
+		}
+		if r.UptimePercentage.Valid != tt.uptime.Valid || math.Abs(r.UptimePercentage.Float64-tt.uptime.Float64) > 1e-9 {
+			t.Errorf("%s: uptime %+v, seharusnya %+v", tt.name, r.UptimePercentage, tt.uptime)
+		}
+	}
+}
+
+func TestExpectedSamples(t *testing.T) {
+	tests := []struct {
+		window, interval time.Duration
+		want             int
+	}{
+		{time.Hour, time.Minute, 60},
+		{time.Hour, 7 * time.Minute, 8},
+		{30 * time.Minute, time.Minute, 30},
+		{time.Hour, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := summary.ExpectedSamples(tt.window, tt.interval); got != tt.want {
+			t.Errorf("ExpectedSamples(%s, %s) = %d, seharusnya %d", tt.window, tt.interval, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package summary
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// Latency adalah statistik response time (ms) dari sampel yang sukses saja
+type Latency struct {
+	Count          int
+	Min, Max, Mean float64
+	P50, P90, P95  float64
+	P99            float64
+	Histogram      *Histogram // nil jika summary.histogram_buckets kosong
+}
+
+// Histogram menyimpan jumlah sampel per bucket. Counts[i] adalah jumlah sampel
+// dengan response time <= LE[i] (dan > LE[i-1]), elemen terakhir Counts untuk
+// yang lebih besar dari bucket terakhir. Batas bucket ikut disimpan supaya
+// histogram dari jam yang berbeda tetap bisa dijumlahkan walaupun konfigurasi
+// berubah.
+type Histogram struct {
+	LE     []float64 `json:"le"`
+	Counts []int     `json:"counts"`
+}
+
+// NewLatency menghitung statistik dari response time sampel sukses, nil jika
+// tidak ada sampel. Slice values akan diurutkan.
+func NewLatency(values []float64, buckets []float64) *Latency {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Float64s(values)
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	l := &Latency{
+		Count: len(values),
+		Min:   values[0],
+		Max:   values[len(values)-1],
+		Mean:  sum / float64(len(values)),
+		P50:   Percentile(values, 50),
+		P90:   Percentile(values, 90),
+		P95:   Percentile(values, 95),
+		P99:   Percentile(values, 99),
+	}
+	if len(buckets) > 0 {
+		l.Histogram = newHistogram(values, buckets)
+	}
+	return l
+}
+
+// Percentile mengembalikan persentil p (0-100) dari slice yang sudah urut,
+// dengan interpolasi linear antar dua nilai terdekat (p50 sama dengan median)
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if upper >= len(sorted) {
+		upper = len(sorted) - 1
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func newHistogram(sorted []float64, buckets []float64) *Histogram {
+	h := &Histogram{LE: buckets, Counts: make([]int, len(buckets)+1)}
+	for _, v := range sorted {
+		i := sort.SearchFloat64s(buckets, v) // bucket pertama dengan batas >= v
+		h.Counts[i]++
+	}
+	return h
+}
+
+// JSON dari histogram untuk kolom rt_histogram
+func (h *Histogram) String() string {
+	b, _ := json.Marshal(h)
+	return string(b)
+}
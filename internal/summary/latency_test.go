@@ -0,0 +1,71 @@
+package summary_test
+
+import (
+	"math"
+	"testing"
+
+	"sla_uptime/internal/summary"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []float64{10, 20, 30, 40, 50}
+	tests := []struct {
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{values, 0, 10},
+		{values, 50, 30},
+		{values, 100, 50},
+		{values, 25, 20},
+		{values, 90, 46},
+		{values, 99, 49.6},
+		{[]float64{10, 20}, 50, 15},
+		{[]float64{7}, 99, 7},
+		{nil, 50, 0},
+	}
+	for _, tt := range tests {
+		if got := summary.Percentile(tt.sorted, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Percentile(%v, %v) = %v, seharusnya %v", tt.sorted, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestNewLatency(t *testing.T) {
+	if l := summary.NewLatency(nil, []float64{10}); l != nil {
+		t.Errorf("tanpa sampel seharusnya nil, dapat %+v", l)
+	}
+
+	l := summary.NewLatency([]float64{40, 10, 30, 20}, nil)
+	if l.Count != 4 || l.Min != 10 || l.Max != 40 || l.Mean != 25 || l.P50 != 25 {
+		t.Errorf("statistik %+v, seharusnya count 4, min 10, max 40, mean 25, p50 25", l)
+	}
+	if l.Histogram != nil {
+		t.Errorf("tanpa bucket seharusnya tanpa histogram")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []float64
+		buckets []float64
+		want    string
+	}{
+		{"batas bucket termasuk", []float64{1, 5, 5.1, 10, 11, 250}, []float64{5, 10, 100},
+			`{"le":[5,10,100],"counts":[2,2,1,1]}`},
+		{"semua di atas bucket terakhir", []float64{500, 900}, []float64{5, 10},
+			`{"le":[5,10],"counts":[0,0,2]}`},
+		{"satu bucket", []float64{0.5}, []float64{1},
+			`{"le":[1],"counts":[1,0]}`},
+	}
+	for _, tt := range tests {
+		l := summary.NewLatency(tt.values, tt.buckets)
+		if l.Histogram == nil {
+			t.Fatalf("%s: histogram nil", tt.name)
+		}
+		if got := l.Histogram.String(); got != tt.want {
+			t.Errorf("%s: %s, seharusnya %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package summary
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/rules"
+)
+
+// Jenis run di ledger
+const (
+	RunScheduled = "scheduled" // jam lalu, dari cron
+	RunBackfill  = "backfill"  // dari -from/-to
+	RunCatchup   = "catchup"   // jam terlewat yang ditemukan otomatis
+)
+
+// Run adalah satu baris ledger: satu jam yang diringkas oleh summarize
+type Run struct {
+	Kind       string // uptime atau downtime
+	Hour       time.Time
+	Type       string
+	Rows       int
+	Err        error
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// RecordRun menyimpan hasil run ke ledger
+func RecordRun(db *sql.DB, table string, run Run) error {
+	status, errText := "ok", sql.NullString{}
+	if run.Err != nil {
+		status, errText = "error", sql.NullString{String: run.Err.Error(), Valid: true}
+	}
+	_, err := db.Exec(`
+        INSERT INTO `+table+` (kind, hour, run_type, status, row_count, error, started_at, finished_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `, run.Kind, run.Hour, run.Type, status, run.Rows, errText, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat run ke %s: %w", table, err)
+	}
+	return nil
+}
+
+// HourRange membulatkan rentang backfill ke jam utuh UTC [from, to). from
+// atau to nol berarti default: jam lalu sampai awal jam sekarang. Jam yang
+// sedang berjalan belum lengkap, jadi to paling jauh awal jam sekarang.
+func HourRange(from, to, now time.Time) (time.Time, time.Time, error) {
+	currentHour := now.UTC().Truncate(time.Hour)
+	if from.IsZero() {
+		from = currentHour.Add(-time.Hour)
+	}
+	if to.IsZero() {
+		to = currentHour
+	}
+	from, to = from.UTC().Truncate(time.Hour), to.UTC().Truncate(time.Hour)
+	if to.After(currentHour) {
+		to = currentHour
+	}
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("-from harus sebelum -to (dan sebelum jam sekarang)")
+	}
+	return from, to, nil
+}
+
+// SummarizeHour meringkas satu jam lewat write lalu mencatatnya di ledger.
+// Jam yang sudah selesai (lihat HourDone) dilewati kecuali force; hasilnya
+// false jika jam itu dilewati.
+func SummarizeHour(db *sql.DB, ledgerTable, summaryTable, kind string, hour time.Time, runType string, force bool, write func(hour time.Time) (int, error)) (bool, error) {
+	if !force {
+		done, err := HourDone(db, ledgerTable, summaryTable, kind, hour)
+		if err != nil {
+			return false, err
+		}
+		if done {
+			return false, nil
+		}
+	}
+
+	run := Run{Kind: kind, Hour: hour, Type: runType, StartedAt: time.Now()}
+	run.Rows, run.Err = write(hour)
+	run.FinishedAt = time.Now()
+	if err := RecordRun(db, ledgerTable, run); err != nil {
+		log.Print(err)
+	}
+	return true, run.Err
+}
+
+// BackfillResult adalah hasil satu kali Backfill
+type BackfillResult struct {
+	Done    []time.Time // jam yang berhasil diringkas
+	Skipped int         // jam yang dilewati karena sudah selesai
+	Failed  int
+}
+
+// Backfill meringkas setiap jam di [from, to) dengan SummarizeHour. Jam yang
+// gagal tidak menghentikan jam berikutnya, error-nya digabung.
+func Backfill(db *sql.DB, ledgerTable, summaryTable, kind string, from, to time.Time, runType string, force bool, write func(hour time.Time) (int, error)) (BackfillResult, error) {
+	var result BackfillResult
+	var errs []error
+	for hour := from; hour.Before(to); hour = hour.Add(time.Hour) {
+		ok, err := SummarizeHour(db, ledgerTable, summaryTable, kind, hour, runType, force, write)
+		switch {
+		case err != nil:
+			log.Printf("Jam %s gagal: %v", hour.Format(time.RFC3339), err)
+			errs = append(errs, err)
+			result.Failed++
+		case ok:
+			result.Done = append(result.Done, hour)
+		default:
+			result.Skipped++
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// HourDone mengecek apakah jam tersebut sudah selesai diringkas: ada run ok di
+// ledger, atau (untuk jam sebelum ada ledger) sudah ada baris di tabel ringkasan
+func HourDone(db *sql.DB, ledgerTable, summaryTable, kind string, hour time.Time) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM "+ledgerTable+" WHERE kind = ? AND hour = ? AND status = 'ok'", kind, hour).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("gagal membaca %s: %w", ledgerTable, err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+	return HourExists(db, summaryTable, hour)
+}
+
+// Awal jam sebagai teks "2006-01-02 15". DATE_FORMAT hanya ada di MySQL,
+// CAST ke teks lalu SUBSTR juga jalan di SQLite yang menyimpan DATETIME
+// sebagai teks dengan awalan yang sama.
+const hourLayout = "2006-01-02 15"
+
+func hourOf(column string) string {
+	return "SUBSTR(CAST(" + column + " AS CHAR), 1, 13)"
+}
+
+// Gaps mencari jam di [from, to) yang punya ping_results lolos filter tapi
+// belum punya ringkasan dan belum pernah selesai diringkas, urut dari yang lama
+func Gaps(db *sql.DB, tables config.TablesConfig, kind string, filter rules.Filter, summaryTable string, from, to time.Time) ([]time.Time, error) {
+	rawHours, err := matchingHours(db, tables.PingResults, filter, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mencari jam di %s: %w", tables.PingResults, err)
+	}
+
+	doneHours, err := queryHours(db, `
+        SELECT DISTINCT `+hourOf("timestamp")+`
+        FROM `+summaryTable+`
+        WHERE timestamp >= ? AND timestamp < ?
+    `, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mencari jam di %s: %w", summaryTable, err)
+	}
+	okHours, err := queryHours(db, `
+        SELECT DISTINCT `+hourOf("hour")+`
+        FROM `+tables.SummaryRuns+`
+        WHERE kind = ? AND status = 'ok' AND hour >= ? AND hour < ?
+    `, kind, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mencari jam di %s: %w", tables.SummaryRuns, err)
+	}
+	for hour := range okHours {
+		doneHours[hour] = struct{}{}
+	}
+
+	var gaps []time.Time
+	for hour := range rawHours {
+		if _, ok := doneHours[hour]; ok {
+			continue
+		}
+		t, err := time.ParseInLocation(hourLayout, hour, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		gaps = append(gaps, t)
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i].Before(gaps[j]) })
+	return gaps, nil
+}
+
+// Jam yang mungkin punya sampel lolos filter. Kombinasi status_id / reason_id
+// per jam diambil dari database, klasifikasinya di Go; jam yang beririsan
+// dengan maintenance ikut dihitung untuk downtime.
+func matchingHours(db *sql.DB, table string, filter rules.Filter, from, to time.Time) (map[string]struct{}, error) {
+	rows, err := db.Query(`
+        SELECT DISTINCT `+hourOf("timestamp")+`, status_id, reason_id
+        FROM `+table+`
+        WHERE timestamp >= ? AND timestamp < ?
+    `, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := make(map[string]struct{})
+	for rows.Next() {
+		var hour string
+		var statusID, reasonID sql.NullInt64
+		if err := rows.Scan(&hour, &statusID, &reasonID); err != nil {
+			return nil, err
+		}
+		start, err := time.ParseInLocation(hourLayout, hour, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		if filter.MayMatch(start, start.Add(time.Hour), statusID, reasonID) {
+			hours[hour] = struct{}{}
+		}
+	}
+	return hours, rows.Err()
+}
+
+func queryHours(db *sql.DB, query string, args ...interface{}) (map[string]struct{}, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := make(map[string]struct{})
+	for rows.Next() {
+		var hour string
+		if err := rows.Scan(&hour); err != nil {
+			return nil, err
+		}
+		hours[hour] = struct{}{}
+	}
+	return hours, rows.Err()
+}
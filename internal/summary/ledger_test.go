@@ -0,0 +1,232 @@
+package summary_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+	"sla_uptime/internal/testdb"
+)
+
+// Tabel ringkasan dan ledger di SQLite, di produksi keduanya di MySQL
+const sqliteLedgerTables = `
+CREATE TABLE summary_uptime (id INTEGER PRIMARY KEY AUTOINCREMENT, ip_id INT NOT NULL, timestamp DATETIME NOT NULL);
+CREATE TABLE summary_runs (id INTEGER PRIMARY KEY AUTOINCREMENT, kind TEXT NOT NULL, hour DATETIME NOT NULL,
+	run_type TEXT NOT NULL, status TEXT NOT NULL, row_count INT NOT NULL DEFAULT 0, error TEXT NULL,
+	started_at DATETIME NOT NULL, finished_at DATETIME NOT NULL)`
+
+func openLedgerSQLite(t *testing.T) (*sql.DB, *config.Config) {
+	t.Helper()
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "ledger.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	cfg := config.Default()
+	if _, err := migrate.Up(db, migrate.SQLite, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(sqliteLedgerTables); err != nil {
+		t.Fatal(err)
+	}
+	return db, &cfg
+}
+
+func TestLedgerGaps(t *testing.T) {
+	backends := []struct {
+		name string
+		open func(t *testing.T) (*sql.DB, *config.Config)
+	}{
+		{"sqlite", openLedgerSQLite},
+		{"mysql", func(t *testing.T) (*sql.DB, *config.Config) { return testdb.MySQL(t) }},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			db, cfg := b.open(t)
+			testLedgerGaps(t, db, cfg)
+		})
+	}
+}
+
+func testLedgerGaps(t *testing.T, db *sql.DB, cfg *config.Config) {
+	tables := cfg.Tables
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+
+	mustExec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.Exec(query, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sample := func(h int, statusID int) {
+		t.Helper()
+		mustExec("INSERT INTO "+tables.PingResults+" (ip_id, timestamp, status, status_id, reason_id) VALUES (1, ?, '1', ?, 1)",
+			hour(h).Add(20*time.Minute), statusID)
+	}
+	record := func(kind string, h int, err error) {
+		t.Helper()
+		run := summary.Run{Kind: kind, Hour: hour(h), Type: summary.RunScheduled, Err: err, StartedAt: hour(h + 1), FinishedAt: hour(h + 1)}
+		if err := summary.RecordRun(db, tables.SummaryRuns, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Jam 0-7 punya sampel sla kecuali jam 4 (hanya status yang tidak masuk
+	// uptime) dan jam 6 (tidak ada sampel sama sekali)
+	for _, h := range []int{0, 1, 2, 3, 5, 7} {
+		sample(h, 7)
+	}
+	sample(4, 99)
+	// jam 1 diringkas sebelum ada ledger, jam 2 dan 7 ok di ledger, jam 3
+	// gagal, jam 0 hanya ok untuk downtime
+	mustExec("INSERT INTO "+tables.SummaryUptime+" (ip_id, timestamp) VALUES (1, ?)", hour(1))
+	record("uptime", 2, nil)
+	record("uptime", 3, errors.New("koneksi putus"))
+	record("uptime", 7, nil)
+	record("downtime", 0, nil)
+
+	set, err := rules.New(rules.FromFilters(cfg.Filters), "filters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := rules.Filter{Set: set, Class: rules.SLA}
+
+	gaps, err := summary.Gaps(db, tables, "uptime", filter, tables.SummaryUptime, hour(0), hour(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(gaps), fmt.Sprint([]time.Time{hour(0), hour(3), hour(5)}); got != want {
+		t.Errorf("Gaps = %s, seharusnya %s", got, want)
+	}
+	// Batas rentang setengah terbuka
+	if gaps, err := summary.Gaps(db, tables, "uptime", filter, tables.SummaryUptime, hour(1), hour(5)); err != nil || fmt.Sprint(gaps) != fmt.Sprint([]time.Time{hour(3)}) {
+		t.Errorf("Gaps [01:00, 05:00) = %v (%v), seharusnya hanya 03:00", gaps, err)
+	}
+
+	tests := []struct {
+		h    int
+		want bool
+	}{
+		{0, false}, // ok hanya untuk downtime
+		{1, true},  // sudah ada ringkasan
+		{2, true},  // ok di ledger
+		{3, false}, // run terakhir gagal
+		{5, false},
+		{7, true},
+	}
+	for _, tt := range tests {
+		done, err := summary.HourDone(db, tables.SummaryRuns, tables.SummaryUptime, "uptime", hour(tt.h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != tt.want {
+			t.Errorf("HourDone(%s) = %v, seharusnya %v", hour(tt.h).Format("15:04"), done, tt.want)
+		}
+	}
+}
+
+func TestHourRange(t *testing.T) {
+	now := time.Date(2024, 1, 31, 10, 25, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 31, h, m, 0, 0, time.UTC) }
+	wib := time.FixedZone("WIB", 7*3600)
+	tests := []struct {
+		name             string
+		from, to         time.Time
+		wantFrom, wantTo time.Time
+		wantErr          bool
+	}{
+		{"default jam lalu", time.Time{}, time.Time{}, at(9, 0), at(10, 0), false},
+		{"dibulatkan ke bawah", at(6, 30), at(8, 45), at(6, 0), at(8, 0), false},
+		{"-to di jam berjalan dibatasi", at(6, 0), at(10, 20), at(6, 0), at(10, 0), false},
+		{"-to di masa depan dibatasi", at(6, 0), at(23, 0), at(6, 0), at(10, 0), false},
+		{"zona waktu lain ke UTC", time.Date(2024, 1, 31, 13, 30, 0, 0, wib), time.Time{}, at(6, 0), at(10, 0), false},
+		{"-from dan -to di jam yang sama", at(6, 10), at(6, 50), time.Time{}, time.Time{}, true},
+		{"-from di jam berjalan", at(10, 5), time.Time{}, time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		from, to, err := summary.HourRange(tt.from, tt.to, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: [%s, %s), seharusnya error", tt.name, from, to)
+			}
+			continue
+		}
+		if err != nil || !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+			t.Errorf("%s: [%s, %s) (%v), seharusnya [%s, %s)", tt.name, from, to, err, tt.wantFrom, tt.wantTo)
+		}
+	}
+}
+
+// Backfill melewati jam yang sudah ada di ledger, -force menghitung ulang
+// semuanya, jam yang gagal dicoba lagi di run berikutnya
+func TestBackfill(t *testing.T) {
+	db, cfg := openLedgerSQLite(t)
+	tables := cfg.Tables
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	ledger := func(h int) {
+		t.Helper()
+		run := summary.Run{Kind: "uptime", Hour: hour(h), Type: summary.RunScheduled, StartedAt: hour(h + 1), FinishedAt: hour(h + 1)}
+		if err := summary.RecordRun(db, tables.SummaryRuns, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ledger(1)
+
+	var written []time.Time
+	fail := map[time.Time]bool{hour(3): true}
+	write := func(h time.Time) (int, error) {
+		written = append(written, h)
+		if fail[h] {
+			return 0, errors.New("koneksi putus")
+		}
+		return 1, nil
+	}
+
+	tests := []struct {
+		name    string
+		force   bool
+		fixed   bool // jam 3 sudah tidak gagal lagi
+		written []int
+		done    []int
+		skipped int
+		failed  int
+	}{
+		{"run pertama", false, false, []int{0, 2, 3}, []int{0, 2}, 1, 1},
+		{"run ulang", false, false, []int{3}, nil, 3, 1},
+		{"jam gagal pulih", false, true, []int{3}, []int{3}, 3, 0},
+		{"semua sudah ada", false, true, nil, nil, 4, 0},
+		{"-force", true, true, []int{0, 1, 2, 3}, []int{0, 1, 2, 3}, 0, 0},
+	}
+	hours := func(list []int) string {
+		out := make([]time.Time, len(list))
+		for i, h := range list {
+			out[i] = hour(h)
+		}
+		return fmt.Sprint(out)
+	}
+	for _, tt := range tests {
+		written = nil
+		if tt.fixed {
+			delete(fail, hour(3))
+		}
+		result, err := summary.Backfill(db, tables.SummaryRuns, tables.SummaryUptime, "uptime", hour(0), hour(4), summary.RunBackfill, tt.force, write)
+		if (err != nil) != (tt.failed > 0) {
+			t.Errorf("%s: error %v", tt.name, err)
+		}
+		if fmt.Sprint(written) != hours(tt.written) || fmt.Sprint(result.Done) != hours(tt.done) ||
+			result.Skipped != tt.skipped || result.Failed != tt.failed {
+			t.Errorf("%s: ditulis %v, selesai %v, dilewati %d, gagal %d, seharusnya %s, %s, %d, %d",
+				tt.name, written, result.Done, result.Skipped, result.Failed, hours(tt.written), hours(tt.done), tt.skipped, tt.failed)
+		}
+	}
+}
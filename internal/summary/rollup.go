@@ -0,0 +1,271 @@
+package summary
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/config"
+)
+
+// Period adalah panjang rollup
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Weekly  Period = "weekly" // mulai hari Senin
+	Monthly Period = "monthly"
+)
+
+// Periods berisi semua rollup, urut dari yang terpendek
+var Periods = []Period{Daily, Weekly, Monthly}
+
+// Start mengembalikan awal periode yang berisi t. Seperti ringkasan per jam,
+// periode dihitung dalam UTC (hari mulai 00:00 UTC).
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch p {
+	case Weekly:
+		offset := (int(day.Weekday()) + 6) % 7 // Senin = 0
+		return day.AddDate(0, 0, -offset)
+	case Monthly:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// Next mengembalikan awal periode berikutnya
+func (p Period) Next(start time.Time) time.Time {
+	switch p {
+	case Weekly:
+		return start.AddDate(0, 0, 7)
+	case Monthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Table mengembalikan nama tabel rollup untuk periode ini
+func (p Period) Table(tables config.TablesConfig) string {
+	switch p {
+	case Weekly:
+		return tables.SummaryUptimeWeekly
+	case Monthly:
+		return tables.SummaryUptimeMonthly
+	default:
+		return tables.SummaryUptimeDaily
+	}
+}
+
+// Agregat satu ip_id dalam satu periode
+type rollupRow struct {
+	ipID                     int
+	hours                    int
+	success, fail            int
+	expected, unknown        int
+	up, down, unknownSeconds float64
+	rtMin, rtMax             sql.NullFloat64
+	rtSum                    float64 // rt_mean x success_count, untuk rata-rata tertimbang
+	rtWeight                 int
+	histogram                *Histogram
+	histogramMismatch        bool
+}
+
+// Satu baris summary_uptime per jam
+type hourRow struct {
+	ipID                     int
+	success, fail            int
+	expected, unknown        int
+	up, down, unknownSeconds float64
+	rtMin, rtMax, rtMean     sql.NullFloat64
+	histogram                sql.NullString
+}
+
+// Rollup menghitung ulang satu periode dari summary_uptime per jam dan
+// mengganti baris periode itu di tabel rollup dalam satu transaksi, lihat
+// aggregateHours.
+func Rollup(db *sql.DB, tables config.TablesConfig, period Period, start time.Time, policy string, sampleSeconds float64) (int, error) {
+	end := period.Next(start)
+	rows, err := db.Query(`
+        SELECT ip_id, success_count, fail_count, expected_count, unknown_count,
+               up_seconds, down_seconds, unknown_seconds, rt_min, rt_max, rt_mean, rt_histogram
+        FROM `+tables.SummaryUptime+`
+        WHERE timestamp >= ? AND timestamp < ?
+        ORDER BY ip_id, timestamp
+    `, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("gagal membaca %s: %w", tables.SummaryUptime, err)
+	}
+	defer rows.Close()
+
+	var hours []hourRow
+	for rows.Next() {
+		var h hourRow
+		err := rows.Scan(&h.ipID, &h.success, &h.fail, &h.expected, &h.unknown,
+			&h.up, &h.down, &h.unknownSeconds, &h.rtMin, &h.rtMax, &h.rtMean, &h.histogram)
+		if err != nil {
+			return 0, fmt.Errorf("gagal membaca %s: %w", tables.SummaryUptime, err)
+		}
+		hours = append(hours, h)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	result := aggregateHours(hours, sampleSeconds)
+	return len(result), writeRollup(db, period.Table(tables), start, result, policy)
+}
+
+// Menjumlahkan baris per jam menjadi satu baris per ip_id, urut ip_id.
+//
+// Semua angka dijumlahkan dari jumlah sampel dan detik up/down per jam, bukan
+// rata-rata persentase per jam. Baris per jam dari sebelum ada up_seconds
+// dihitung sebagai jumlah sampel x sampleSeconds. rt_mean dirata-rata dengan
+// bobot success_count, histogram dijumlahkan jika batas bucket-nya sama.
+func aggregateHours(hours []hourRow, sampleSeconds float64) []*rollupRow {
+	byIP := make(map[int]*rollupRow)
+	for _, h := range hours {
+		r, ok := byIP[h.ipID]
+		if !ok {
+			r = &rollupRow{ipID: h.ipID}
+			byIP[h.ipID] = r
+		}
+		r.hours++
+		r.success += h.success
+		r.fail += h.fail
+		r.expected += h.expected
+		r.unknown += h.unknown
+		if h.up+h.down+h.unknownSeconds > 0 {
+			r.up += h.up
+			r.down += h.down
+			r.unknownSeconds += h.unknownSeconds
+		} else {
+			r.up += float64(h.success) * sampleSeconds
+			r.down += float64(h.fail) * sampleSeconds
+			r.unknownSeconds += float64(h.unknown) * sampleSeconds
+		}
+		if h.rtMin.Valid && (!r.rtMin.Valid || h.rtMin.Float64 < r.rtMin.Float64) {
+			r.rtMin = h.rtMin
+		}
+		if h.rtMax.Valid && (!r.rtMax.Valid || h.rtMax.Float64 > r.rtMax.Float64) {
+			r.rtMax = h.rtMax
+		}
+		if h.rtMean.Valid && h.success > 0 {
+			r.rtSum += h.rtMean.Float64 * float64(h.success)
+			r.rtWeight += h.success
+		}
+		r.mergeHistogram(h.histogram)
+	}
+
+	result := make([]*rollupRow, 0, len(byIP))
+	for _, r := range byIP {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ipID < result[j].ipID })
+	return result
+}
+
+// Persentase waktu yang terukur (up + down), 100 jika tidak ada waktu sama sekali
+func (r *rollupRow) coverage() float64 {
+	if total := r.up + r.down + r.unknownSeconds; total > 0 {
+		return math.Min(100, (r.up+r.down)/total*100)
+	}
+	return 100
+}
+
+// rt_mean tertimbang success_count, NULL tanpa sampel sukses
+func (r *rollupRow) rtMean() sql.NullFloat64 {
+	if r.rtWeight > 0 {
+		return sql.NullFloat64{Float64: r.rtSum / float64(r.rtWeight), Valid: true}
+	}
+	return sql.NullFloat64{}
+}
+
+// rt_histogram periode, NULL jika tidak ada atau batas bucket berbeda
+func (r *rollupRow) histogramValue() sql.NullString {
+	if r.histogram != nil && !r.histogramMismatch {
+		return sql.NullString{String: r.histogram.String(), Valid: true}
+	}
+	return sql.NullString{}
+}
+
+// Histogram jam tanpa sampel sukses (NULL) tidak mengubah jumlah. Jika batas
+// bucket berbeda antar jam, histogram periode dikosongkan.
+func (r *rollupRow) mergeHistogram(raw sql.NullString) {
+	if !raw.Valid || r.histogramMismatch {
+		return
+	}
+	var h Histogram
+	if err := json.Unmarshal([]byte(raw.String), &h); err != nil || len(h.Counts) != len(h.LE)+1 {
+		r.histogramMismatch = true
+		return
+	}
+	if r.histogram == nil {
+		r.histogram = &h
+		return
+	}
+	if !equalBounds(r.histogram.LE, h.LE) {
+		r.histogramMismatch = true
+		return
+	}
+	for i, c := range h.Counts {
+		r.histogram.Counts[i] += c
+	}
+}
+
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeRollup(db *sql.DB, table string, start time.Time, rows []*rollupRow, policy string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM "+table+" WHERE period_start = ?", start); err != nil {
+		return fmt.Errorf("gagal menghapus rollup lama di %s: %w", table, err)
+	}
+
+	stmt, err := tx.Prepare(`
+        INSERT INTO ` + table + ` (ip_id, period_start, hours, success_count, fail_count, expected_count, unknown_count,
+                                   up_seconds, down_seconds, unknown_seconds, uptime_percentage, coverage_percentage,
+                                   rt_min, rt_max, rt_mean, rt_histogram, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `)
+	if err != nil {
+		return fmt.Errorf("gagal mempersiapkan insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, r := range rows {
+		_, err := stmt.Exec(r.ipID, start, r.hours, r.success, r.fail, r.expected, r.unknown,
+			r.up, r.down, r.unknownSeconds, Uptime(r.up, r.down, r.unknownSeconds, policy), r.coverage(),
+			r.rtMin, r.rtMax, r.rtMean(), r.histogramValue(), now)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan rollup ip_id %d ke %s: %w", r.ipID, table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package summary
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestAggregateHours(t *testing.T) {
+	f := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	hist := func(raw string) sql.NullString { return sql.NullString{String: raw, Valid: raw != ""} }
+	// Jam dengan sampel sukses: rt_min = mean/2, rt_max = mean*2
+	hour := func(ipID int, up, down, unknown float64, success int, mean float64, histogram string) hourRow {
+		h := hourRow{ipID: ipID, success: success, up: up, down: down, unknownSeconds: unknown, histogram: hist(histogram)}
+		if success > 0 {
+			h.rtMin, h.rtMax, h.rtMean = f(mean/2), f(mean*2), f(mean)
+		}
+		return h
+	}
+	legacy := func(ipID, success, fail, unknown int) hourRow {
+		return hourRow{ipID: ipID, success: success, fail: fail, unknown: unknown}
+	}
+
+	type want struct {
+		hours, success    int
+		up, down, unknown float64
+		coverage          float64
+		rtMin, rtMax      float64 // NaN = NULL
+		rtMean            float64 // NaN = NULL
+		histogram         string  // kosong = NULL
+	}
+	null := math.NaN()
+	tests := []struct {
+		name  string
+		hours []hourRow
+		want  want
+	}{
+		// Rata-rata persentase per jam = 66.7%, tertimbang waktu = 6600 / 10200
+		{"tertimbang waktu dan success_count", []hourRow{
+			hour(1, 3000, 0, 600, 50, 10, `{"le":[10,100],"counts":[30,20,0]}`),
+			hour(1, 0, 3600, 0, 0, 0, ""),
+			hour(1, 3600, 0, 0, 10, 40, `{"le":[10,100],"counts":[0,5,5]}`),
+		}, want{3, 60, 6600, 3600, 600, 10200.0 / 10800 * 100, 5, 80, (10*50 + 40*10) / 60.0, `{"le":[10,100],"counts":[30,25,5]}`}},
+		{"batas bucket berbeda", []hourRow{
+			hour(1, 3600, 0, 0, 10, 20, `{"le":[10,100],"counts":[5,5,0]}`),
+			hour(1, 3600, 0, 0, 10, 20, `{"le":[50],"counts":[10,0]}`),
+		}, want{2, 20, 7200, 0, 0, 100, 10, 40, 20, ""}},
+		{"histogram rusak", []hourRow{
+			hour(1, 3600, 0, 0, 10, 20, `{"le":[10],"counts":[5]}`),
+			hour(1, 3600, 0, 0, 10, 20, `{"le":[10],"counts":[5,5]}`),
+		}, want{2, 20, 7200, 0, 0, 100, 10, 40, 20, ""}},
+		{"baris lama tanpa detik", []hourRow{legacy(1, 45, 15, 0), legacy(1, 0, 0, 60)},
+			want{2, 45, 2700, 900, 3600, 50, null, null, null, ""}},
+		{"tanpa waktu sama sekali", []hourRow{legacy(1, 0, 0, 0)},
+			want{1, 0, 0, 0, 0, 100, null, null, null, ""}},
+	}
+	nullEqual := func(v sql.NullFloat64, want float64) bool {
+		if math.IsNaN(want) {
+			return !v.Valid
+		}
+		return v.Valid && math.Abs(v.Float64-want) < 1e-9
+	}
+	for _, tt := range tests {
+		rows := aggregateHours(tt.hours, 60)
+		if len(rows) != 1 {
+			t.Errorf("%s: %d baris, seharusnya 1", tt.name, len(rows))
+			continue
+		}
+		r, w := rows[0], tt.want
+		if r.hours != w.hours || r.success != w.success || r.up != w.up || r.down != w.down || r.unknownSeconds != w.unknown ||
+			math.Abs(r.coverage()-w.coverage) > 1e-9 {
+			t.Errorf("%s: %d jam, %d sukses, up %v down %v unknown %v coverage %v, seharusnya %d, %d, %v, %v, %v, %v",
+				tt.name, r.hours, r.success, r.up, r.down, r.unknownSeconds, r.coverage(), w.hours, w.success, w.up, w.down, w.unknown, w.coverage)
+		}
+		if !nullEqual(r.rtMin, w.rtMin) || !nullEqual(r.rtMax, w.rtMax) || !nullEqual(r.rtMean(), w.rtMean) {
+			t.Errorf("%s: rt_min %+v rt_max %+v rt_mean %+v, seharusnya %v %v %v", tt.name, r.rtMin, r.rtMax, r.rtMean(), w.rtMin, w.rtMax, w.rtMean)
+		}
+		if got := r.histogramValue(); got.String != w.histogram || got.Valid != (w.histogram != "") {
+			t.Errorf("%s: histogram %+v, seharusnya %q", tt.name, got, w.histogram)
+		}
+	}
+
+	// Satu baris per ip_id, urut ip_id
+	rows := aggregateHours([]hourRow{legacy(3, 1, 0, 0), legacy(1, 1, 0, 0), legacy(3, 1, 0, 0), legacy(2, 1, 0, 0)}, 60)
+	var got []string
+	for _, r := range rows {
+		got = append(got, fmt.Sprintf("%d:%d", r.ipID, r.hours))
+	}
+	if fmt.Sprint(got) != "[1:1 2:1 3:2]" {
+		t.Errorf("baris %v, seharusnya [1:1 2:1 3:2]", got)
+	}
+}
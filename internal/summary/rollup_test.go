@@ -0,0 +1,133 @@
+package summary_test
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/summary"
+	"sla_uptime/internal/testdb"
+)
+
+func TestPeriodStart(t *testing.T) {
+	at := time.Date(2024, 2, 29, 13, 45, 0, 0, time.UTC) // Kamis
+	tests := []struct {
+		period      summary.Period
+		at          time.Time
+		start, next time.Time
+	}{
+		{summary.Daily, at, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{summary.Weekly, at, time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{summary.Weekly, time.Date(2024, 3, 3, 23, 0, 0, 0, time.UTC), time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{summary.Monthly, at, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		// periode selalu dalam UTC, bukan zona waktu t
+		{summary.Daily, time.Date(2024, 3, 1, 5, 0, 0, 0, time.FixedZone("UTC+7", 7*3600)),
+			time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		start := tt.period.Start(tt.at)
+		if !start.Equal(tt.start) {
+			t.Errorf("%s Start(%s) = %s, seharusnya %s", tt.period, tt.at, start, tt.start)
+		}
+		if next := tt.period.Next(start); !next.Equal(tt.next) {
+			t.Errorf("%s Next(%s) = %s, seharusnya %s", tt.period, start, next, tt.next)
+		}
+	}
+}
+
+// Penjumlahannya di TestAggregateHours, di sini hanya pembacaan jam di periode
+// itu (termasuk baris lama tanpa up_seconds) dan penyimpanan rollup
+func TestRollupWeighting(t *testing.T) {
+	db, cfg := testdb.MySQL(t)
+	tables := cfg.Tables
+	day := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	histogram := func(counts ...int) *summary.Histogram {
+		return &summary.Histogram{LE: []float64{10, 100}, Counts: counts}
+	}
+	// WriteUptime mengganti satu jam utuh, jadi baris dikumpulkan per jam dulu
+	byHour := make(map[time.Time][]summary.Row)
+	hour := func(h int, ipID int, up, down, unknown float64, success int, mean float64, hist *summary.Histogram) {
+		row := summary.Row{
+			IPID: ipID, Timestamp: day.Add(time.Duration(h) * time.Hour),
+			SuccessCount: success, UpSeconds: up, DownSeconds: down, UnknownSeconds: unknown,
+			UptimePercentage: summary.Uptime(up, down, unknown, summary.MissingExcluded),
+		}
+		if success > 0 {
+			row.Latency = &summary.Latency{Count: success, Min: mean / 2, Max: mean * 2, Mean: mean, P50: mean, Histogram: hist}
+		}
+		byHour[row.Timestamp] = append(byHour[row.Timestamp], row)
+	}
+
+	// ip_id 1: satu jam hampir penuh up dengan banyak sampel, satu jam down
+	// penuh tanpa sampel sukses. Rata-rata persentase per jam = 66.7%,
+	// tertimbang waktu = 6600 / (6600 + 3600).
+	hour(1, 1, 3000, 0, 600, 50, 10, histogram(30, 20, 0))
+	hour(2, 1, 0, 3600, 0, 0, 0, nil)
+	hour(3, 1, 3600, 0, 0, 10, 40, histogram(0, 5, 5))
+	// jam di hari berikutnya tidak ikut
+	hour(24, 1, 0, 3600, 0, 0, 0, nil)
+	for at, rows := range byHour {
+		if err := summary.WriteUptime(db, tables.SummaryUptime, at, rows); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// ip_id 2: baris lama tanpa up_seconds dihitung dari jumlah sampel x 60 detik
+	if _, err := db.Exec("INSERT INTO "+tables.SummaryUptime+" (ip_id, timestamp, success_count, fail_count, unknown_count) VALUES (2, ?, 45, 15, 0)",
+		day.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := summary.Rollup(db, tables, summary.Daily, day, summary.MissingExcluded, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("%d target di rollup, seharusnya 2", n)
+	}
+
+	type rollup struct {
+		hours, success    int
+		up, down, unknown float64
+		uptime, rtMean    sql.NullFloat64
+		rtMin, rtMax      sql.NullFloat64
+		histogram         sql.NullString
+	}
+	read := func(ipID int) rollup {
+		t.Helper()
+		var r rollup
+		err := db.QueryRow("SELECT hours, success_count, up_seconds, down_seconds, unknown_seconds, uptime_percentage, rt_mean, rt_min, rt_max, rt_histogram FROM "+
+			tables.SummaryUptimeDaily+" WHERE ip_id = ? AND period_start = ?", ipID, day).
+			Scan(&r.hours, &r.success, &r.up, &r.down, &r.unknown, &r.uptime, &r.rtMean, &r.rtMin, &r.rtMax, &r.histogram)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	near := func(v sql.NullFloat64, want float64) bool {
+		return v.Valid && math.Abs(v.Float64-want) < 1e-3
+	}
+
+	one := read(1)
+	if one.hours != 3 || one.success != 60 || one.up != 6600 || one.down != 3600 || one.unknown != 600 {
+		t.Errorf("ip_id 1: %+v, seharusnya 3 jam, 60 sukses, up 6600 down 3600 unknown 600", one)
+	}
+	if !near(one.uptime, 6600.0/10200*100) {
+		t.Errorf("ip_id 1 uptime %+v, seharusnya %.3f (tertimbang waktu)", one.uptime, 6600.0/10200*100)
+	}
+	if !near(one.rtMean, (10*50+40*10)/60.0) {
+		t.Errorf("ip_id 1 rt_mean %+v, seharusnya %.3f (tertimbang success_count)", one.rtMean, (10*50+40*10)/60.0)
+	}
+	if !near(one.rtMin, 5) || !near(one.rtMax, 80) {
+		t.Errorf("ip_id 1 rt_min %+v rt_max %+v, seharusnya 5 dan 80", one.rtMin, one.rtMax)
+	}
+	if want := `{"le":[10,100],"counts":[30,25,5]}`; one.histogram.String != want {
+		t.Errorf("ip_id 1 histogram %+v, seharusnya %s", one.histogram, want)
+	}
+
+	two := read(2)
+	if two.up != 45*60 || two.down != 15*60 || !near(two.uptime, 75) || two.rtMean.Valid || two.histogram.Valid {
+		t.Errorf("ip_id 2: %+v, seharusnya up 2700 down 900 uptime 75, tanpa rt_mean dan histogram", two)
+	}
+}
@@ -0,0 +1,242 @@
+// Package summary menghitung ringkasan per jam dari ping_results
+// (uptime berdasarkan waktu, jumlah sukses/gagal, median response time).
+package summary
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"sla_uptime/internal/calendar"
+	"sla_uptime/internal/rules"
+)
+
+// Row adalah ringkasan satu ip_id untuk satu rentang waktu
+type Row struct {
+	IPID             int
+	Timestamp        time.Time
+	SuccessCount     int
+	FailCount        int
+	UptimePercentage sql.NullFloat64 // NULL jika tidak ada waktu yang dihitung
+	Latency          *Latency        // nil jika tidak ada sampel sukses
+
+	// Lama status up/down dari selisih antar sampel (diisi Collect) dan
+	// sisanya yang tidak tertutup sampel (diisi ApplyCoverage), dalam detik
+	UpSeconds      float64
+	DownSeconds    float64
+	UnknownSeconds float64
+
+	// Diisi ApplyCoverage: sampel yang seharusnya ada, yang tidak tercatat,
+	// dan persentase waktu yang tertutup sampel
+	ExpectedCount      int
+	UnknownCount       int
+	CoveragePercentage float64
+}
+
+// Options mengatur perhitungan Collect
+type Options struct {
+	MaxGap    time.Duration // lama maksimum status satu sampel berlaku
+	Buckets   []float64     // batas bucket histogram response time (ms), kosong = tanpa histogram
+	Calendars *calendar.Set // jam operasional per target, nil = semua target 24 jam
+}
+
+// Collect membaca ping_results di [from, to), lalu menghitung ringkasan per
+// ip_id dari sampel yang lolos filter. Timestamp setiap Row diisi from.
+// Sampel yang tepat di to masuk ke rentang berikutnya, jadi tidak dihitung
+// dua kali oleh dua jam yang berurutan.
+//
+// Selain jumlah sampel, waktu up/down dihitung dari selisih antar sampel
+// berurutan: status satu sampel berlaku sampai sampel berikutnya, paling lama
+// opts.MaxGap. Sampel yang tidak lolos filter (misalnya status_id berubah jadi
+// pekerjaan terjadwal atau masuk jendela maintenance) menghentikan status
+// sampel sebelumnya. Sampel terakhir
+// sebelum from ikut dipakai untuk menutup awal jam. Statistik response time
+// hanya dari sampel yang sukses.
+//
+// Untuk target yang punya kalender (opts.Calendars), sampel di luar jam
+// operasional diperlakukan seperti sampel yang tidak lolos filter dan waktu
+// up/down hanya dihitung di dalam jam operasional.
+func Collect(db *sql.DB, table string, filter rules.Filter, from, to time.Time, opts Options) ([]Row, error) {
+	maxGap := opts.MaxGap
+	seeds, err := lastBefore(db, table, filter, from, maxGap)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+        SELECT ip_id, timestamp, status, response_time, status_id, reason_id
+        FROM `+table+`
+        WHERE timestamp >= ? AND timestamp < ?
+        ORDER BY ip_id, timestamp
+    `, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menjalankan query: %w", err)
+	}
+	defer rows.Close()
+
+	ipData := make(map[int][]float64)   // response time sampel sukses
+	statusCount := make(map[int][2]int) // Index 0: fail_count, Index 1: success_count
+	timelines := make(map[int]*timeline)
+
+	for rows.Next() {
+		var ipID int
+		var timestamp time.Time
+		var status int
+		var responseTime float64
+		var statusID, reasonID sql.NullInt64
+
+		if err := rows.Scan(&ipID, &timestamp, &status, &responseTime, &statusID, &reasonID); err != nil {
+			log.Printf("Warning: Gagal membaca baris: %v", err)
+			continue
+		}
+
+		tl, ok := timelines[ipID]
+		if !ok {
+			tl = &timeline{from: from, to: to, maxGap: maxGap, cal: opts.Calendars.For(ipID)}
+			if seed, ok := seeds[ipID]; ok && tl.cal.Covers(seed.at) {
+				tl.add(seed.at, seed.up)
+			}
+			timelines[ipID] = tl
+		}
+		if !filter.Match(ipID, timestamp, statusID, reasonID) || !tl.cal.Covers(timestamp) {
+			tl.stop(timestamp)
+			continue
+		}
+		tl.add(timestamp, status == 1)
+
+		if status == 1 {
+			ipData[ipID] = append(ipData[ipID], responseTime)
+		}
+		counts := statusCount[ipID]
+		if status == 1 {
+			counts[1]++
+		} else {
+			counts[0]++
+		}
+		statusCount[ipID] = counts
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error setelah iterasi rows: %w", err)
+	}
+
+	result := make([]Row, 0, len(statusCount))
+	for ipID, counts := range statusCount {
+		successCount := counts[1]
+		failCount := counts[0]
+
+		totalPings := successCount + failCount
+		var uptimePercentage sql.NullFloat64
+		if totalPings > 0 {
+			uptimePercentage = sql.NullFloat64{Float64: float64(successCount) / float64(totalPings) * 100, Valid: true}
+		}
+
+		tl := timelines[ipID]
+		tl.close()
+		latency := NewLatency(ipData[ipID], opts.Buckets)
+		result = append(result, Row{
+			IPID:             ipID,
+			Timestamp:        from,
+			SuccessCount:     successCount,
+			FailCount:        failCount,
+			UptimePercentage: uptimePercentage,
+			Latency:          latency,
+			UpSeconds:        tl.up.Seconds(),
+			DownSeconds:      tl.down.Seconds(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].IPID < result[j].IPID })
+
+	return result, nil
+}
+
+type seed struct {
+	at time.Time
+	up bool
+}
+
+// Sampel terakhir setiap ip_id dalam maxGap sebelum from, hanya jika sampel
+// itu lolos filter
+func lastBefore(db *sql.DB, table string, filter rules.Filter, from time.Time, maxGap time.Duration) (map[int]seed, error) {
+	rows, err := db.Query(`
+        SELECT p.ip_id, p.timestamp, p.status, p.status_id, p.reason_id
+        FROM `+table+` p
+        JOIN (
+            SELECT ip_id, MAX(timestamp) AS last_timestamp
+            FROM `+table+`
+            WHERE timestamp >= ? AND timestamp < ?
+            GROUP BY ip_id
+        ) l ON l.ip_id = p.ip_id AND l.last_timestamp = p.timestamp
+    `, from.Add(-maxGap), from)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca sampel sebelum %s: %w", from.Format(time.RFC3339), err)
+	}
+	defer rows.Close()
+
+	seeds := make(map[int]seed)
+	for rows.Next() {
+		var ipID, status int
+		var at time.Time
+		var statusID, reasonID sql.NullInt64
+		if err := rows.Scan(&ipID, &at, &status, &statusID, &reasonID); err != nil {
+			return nil, fmt.Errorf("gagal membaca sampel sebelum %s: %w", from.Format(time.RFC3339), err)
+		}
+		if filter.Match(ipID, at, statusID, reasonID) {
+			seeds[ipID] = seed{at: at, up: status == 1}
+		}
+	}
+	return seeds, rows.Err()
+}
+
+// timeline menjumlahkan lama up/down satu ip_id di dalam [from, to)
+type timeline struct {
+	from, to time.Time
+	maxGap   time.Duration
+	cal      *calendar.Calendar // nil = 24 jam
+
+	up, down time.Duration
+	last     *seed
+}
+
+// Menambahkan sampel berikutnya; status sampel sebelumnya berlaku sampai at
+func (t *timeline) add(at time.Time, up bool) {
+	t.segment(at)
+	t.last = &seed{at: at, up: up}
+}
+
+// Menghentikan status sampel sebelumnya di at tanpa memulai status baru
+func (t *timeline) stop(at time.Time) {
+	t.segment(at)
+	t.last = nil
+}
+
+// Menutup sampel terakhir sampai akhir rentang
+func (t *timeline) close() {
+	t.segment(t.to)
+	t.last = nil
+}
+
+func (t *timeline) segment(until time.Time) {
+	if t.last == nil {
+		return
+	}
+	start, end := t.last.at, until
+	if limit := t.last.at.Add(t.maxGap); end.After(limit) {
+		end = limit // jeda terlalu lama, sisanya unknown
+	}
+	if start.Before(t.from) {
+		start = t.from
+	}
+	if end.After(t.to) {
+		end = t.to
+	}
+	if !end.After(start) {
+		return
+	}
+	if t.last.up {
+		t.up += t.cal.Covered(start, end)
+	} else {
+		t.down += t.cal.Covered(start, end)
+	}
+}
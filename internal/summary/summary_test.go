@@ -0,0 +1,91 @@
+package summary_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/rules"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/summary"
+)
+
+// Sampel yang tepat di pergantian jam hanya boleh masuk ke jam yang dimulai
+// di timestamp itu, bukan ke jam sebelumnya juga
+func TestCollectBoundarySamples(t *testing.T) {
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "ping.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cfg := config.Default()
+	tables := cfg.Tables
+	if _, err := migrate.Up(db, migrate.SQLite, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	hour := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	samples := []struct {
+		at     time.Time
+		status int
+	}{
+		{hour, 1},                                 // tepat di awal jam
+		{hour.Add(30 * time.Minute), 1},           // di tengah jam
+		{hour.Add(time.Hour), 0},                  // tepat di awal jam berikutnya
+		{hour.Add(time.Hour + 30*time.Minute), 1}, // di tengah jam berikutnya
+	}
+	for _, s := range samples {
+		_, err := db.Exec("INSERT INTO "+tables.PingResults+" (ip_id, timestamp, status, response_time) VALUES (?, ?, ?, ?)",
+			1, s.at, s.status, 10.0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set, err := rules.New([]rules.Rule{{Class: rules.SLA}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := rules.Filter{Set: set, Class: rules.SLA}
+	opts := summary.Options{MaxGap: time.Hour}
+
+	collect := func(from time.Time) summary.Row {
+		t.Helper()
+		rows, err := summary.Collect(db, tables.PingResults, filter, from, from.Add(time.Hour), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 {
+			t.Fatalf("jam %s: %d baris, seharusnya 1", from.Format(time.RFC3339), len(rows))
+		}
+		return rows[0]
+	}
+
+	first := collect(hour)
+	if first.SuccessCount != 2 || first.FailCount != 0 {
+		t.Errorf("jam pertama: sukses %d gagal %d, seharusnya 2 dan 0", first.SuccessCount, first.FailCount)
+	}
+	if first.UpSeconds != 3600 || first.DownSeconds != 0 {
+		t.Errorf("jam pertama: up %.0fs down %.0fs, seharusnya 3600s dan 0s", first.UpSeconds, first.DownSeconds)
+	}
+
+	second := collect(hour.Add(time.Hour))
+	if second.SuccessCount != 1 || second.FailCount != 1 {
+		t.Errorf("jam kedua: sukses %d gagal %d, seharusnya 1 dan 1", second.SuccessCount, second.FailCount)
+	}
+	if second.UpSeconds != 1800 || second.DownSeconds != 1800 {
+		t.Errorf("jam kedua: up %.0fs down %.0fs, seharusnya 1800s dan 1800s", second.UpSeconds, second.DownSeconds)
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + tables.PingResults).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	counted := first.SuccessCount + first.FailCount + second.SuccessCount + second.FailCount
+	if counted != total {
+		t.Errorf("%d sampel dihitung dari %d sampel, ada yang dihitung dua kali atau hilang", counted, total)
+	}
+}
@@ -0,0 +1,69 @@
+package summary
+
+import (
+	"testing"
+	"time"
+
+	"sla_uptime/internal/calendar"
+)
+
+func TestTimeline(t *testing.T) {
+	from := time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC) // Senin
+	min := func(m int) time.Time { return from.Add(time.Duration(m) * time.Minute) }
+
+	// Sampel up / down, atau stop (sampel yang tidak lolos filter)
+	type event struct {
+		at   time.Time
+		up   bool
+		stop bool
+	}
+	office, err := calendar.New("kantor", "UTC", "mon 10:00-10:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		maxGap   time.Duration
+		cal      *calendar.Calendar
+		events   []event
+		up, down time.Duration
+	}{
+		{"tanpa sampel", 5 * time.Minute, nil, nil, 0, 0},
+		{"sampel rapat sampai akhir jam", 5 * time.Minute, nil,
+			[]event{{at: min(0), up: true}, {at: min(1), up: true}, {at: min(2)}, {at: min(57), up: true}},
+			2*time.Minute + 3*time.Minute, 5 * time.Minute},
+		{"jeda lebih dari max_gap jadi unknown", 5 * time.Minute, nil,
+			[]event{{at: min(0), up: true}, {at: min(20)}},
+			5 * time.Minute, 5 * time.Minute},
+		{"sampel sebelum from hanya dihitung mulai from", 5 * time.Minute, nil,
+			[]event{{at: min(-2)}, {at: min(1), up: true}},
+			5 * time.Minute, time.Minute},
+		{"sampel terakhir dipotong di to", 10 * time.Minute, nil,
+			[]event{{at: min(55)}},
+			0, 5 * time.Minute},
+		{"stop menghentikan status sebelumnya", 10 * time.Minute, nil,
+			[]event{{at: min(0), up: true}, {at: min(2), stop: true}, {at: min(8)}},
+			2 * time.Minute, 10 * time.Minute},
+		{"sampel di waktu yang sama", 5 * time.Minute, nil,
+			[]event{{at: min(0), up: true}, {at: min(0)}},
+			0, 5 * time.Minute},
+		{"hanya jam operasional yang dihitung", time.Hour, office,
+			[]event{{at: min(20), up: true}},
+			10 * time.Minute, 0},
+	}
+	for _, tt := range tests {
+		tl := &timeline{from: from, to: from.Add(time.Hour), maxGap: tt.maxGap, cal: tt.cal}
+		for _, e := range tt.events {
+			if e.stop {
+				tl.stop(e.at)
+			} else {
+				tl.add(e.at, e.up)
+			}
+		}
+		tl.close()
+		if tl.up != tt.up || tl.down != tt.down {
+			t.Errorf("%s: up %s down %s, seharusnya up %s down %s", tt.name, tl.up, tl.down, tt.up, tt.down)
+		}
+	}
+}
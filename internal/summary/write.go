@@ -0,0 +1,148 @@
+package summary
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kolom tabel ringkasan dan cara mengambil nilainya dari Row
+type column struct {
+	name  string
+	value func(Row) interface{}
+}
+
+var (
+	keyColumns = []column{
+		{"ip_id", func(r Row) interface{} { return r.IPID }},
+		{"timestamp", func(r Row) interface{} { return r.Timestamp }},
+	}
+	uptimeColumn = column{"uptime_percentage", func(r Row) interface{} { return r.UptimePercentage }}
+
+	// Kolom yang sama di summary_uptime, summary_downtime dan uptime_summary
+	measureColumns = []column{
+		{"success_count", func(r Row) interface{} { return r.SuccessCount }},
+		{"fail_count", func(r Row) interface{} { return r.FailCount }},
+		{"expected_count", func(r Row) interface{} { return r.ExpectedCount }},
+		{"unknown_count", func(r Row) interface{} { return r.UnknownCount }},
+		{"coverage_percentage", func(r Row) interface{} { return r.CoveragePercentage }},
+		{"up_seconds", func(r Row) interface{} { return r.UpSeconds }},
+		{"down_seconds", func(r Row) interface{} { return r.DownSeconds }},
+		{"unknown_seconds", func(r Row) interface{} { return r.UnknownSeconds }},
+		latencyColumn("response_time", func(l *Latency) interface{} { return l.P50 }),
+		latencyColumn("rt_min", func(l *Latency) interface{} { return l.Min }),
+		latencyColumn("rt_max", func(l *Latency) interface{} { return l.Max }),
+		latencyColumn("rt_mean", func(l *Latency) interface{} { return l.Mean }),
+		latencyColumn("rt_p50", func(l *Latency) interface{} { return l.P50 }),
+		latencyColumn("rt_p90", func(l *Latency) interface{} { return l.P90 }),
+		latencyColumn("rt_p95", func(l *Latency) interface{} { return l.P95 }),
+		latencyColumn("rt_p99", func(l *Latency) interface{} { return l.P99 }),
+		latencyColumn("rt_histogram", func(l *Latency) interface{} {
+			if l.Histogram == nil {
+				return nil
+			}
+			return l.Histogram.String()
+		}),
+	}
+)
+
+// Kolom statistik response time, NULL jika tidak ada sampel sukses
+func latencyColumn(name string, value func(*Latency) interface{}) column {
+	return column{name, func(r Row) interface{} {
+		if r.Latency == nil {
+			return nil
+		}
+		return value(r.Latency)
+	}}
+}
+
+// WriteUptime menyimpan ringkasan satu jam ke summary_uptime dalam satu transaksi.
+// Semua baris lama untuk jam tersebut diganti, jadi ip_id yang tidak lagi lolos
+// filter ikut hilang saat dihitung ulang.
+func WriteUptime(db *sql.DB, table string, hour time.Time, rows []Row) error {
+	return writeRows(db, table, hour, append([]column{uptimeColumn}, measureColumns...), true, rows)
+}
+
+// WriteDowntime menyimpan ringkasan pekerjaan terjadwal satu jam ke summary_downtime,
+// baris lama untuk jam tersebut diganti
+func WriteDowntime(db *sql.DB, table string, hour time.Time, rows []Row) error {
+	return writeRows(db, table, hour, measureColumns, true, rows)
+}
+
+// WriteUptimeSummary menyimpan ringkasan hasil upload dari SQLite ke uptime_summary
+func WriteUptimeSummary(db *sql.DB, table string, rows []Row) error {
+	return writeRows(db, table, time.Time{}, append([]column{uptimeColumn}, measureColumns...), false, rows)
+}
+
+// Menyusun INSERT untuk kolom kunci + columns. Dengan upsert, baris dengan
+// (ip_id, timestamp) yang sama ditimpa.
+func writeRows(db *sql.DB, table string, hour time.Time, columns []column, upsert bool, rows []Row) error {
+	all := append(append([]column{}, keyColumns...), columns...)
+	names := make([]string, len(all))
+	for i, c := range all {
+		names[i] = c.name
+	}
+
+	query := "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ")\n" +
+		"VALUES (" + strings.TrimSuffix(strings.Repeat("?, ", len(all)), ", ") + ")"
+	if upsert {
+		updates := make([]string, len(columns))
+		for i, c := range columns {
+			updates[i] = c.name + " = VALUES(" + c.name + ")"
+		}
+		query += "\nON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+
+	return writeTx(db, table, hour, query, rows, func(r Row) []interface{} {
+		args := make([]interface{}, len(all))
+		for i, c := range all {
+			args[i] = c.value(r)
+		}
+		return args
+	})
+}
+
+// HourExists mengecek apakah jam tersebut sudah punya ringkasan di table
+func HourExists(db *sql.DB, table string, hour time.Time) (bool, error) {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE timestamp = ?", hour).Scan(&count); err != nil {
+		return false, fmt.Errorf("gagal mengecek ringkasan %s jam %s: %w", table, hour.Format(time.RFC3339), err)
+	}
+	return count > 0, nil
+}
+
+// Menjalankan query untuk setiap baris dalam satu transaksi. Jika hour tidak
+// nol, baris table untuk jam tersebut dihapus dulu di transaksi yang sama.
+func writeTx(db *sql.DB, table string, hour time.Time, query string, rows []Row, args func(Row) []interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+
+	if !hour.IsZero() {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE timestamp = ?", hour); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("gagal menghapus ringkasan lama: %w", err)
+		}
+	}
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("gagal mempersiapkan insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		if _, err := stmt.Exec(args(r)...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("gagal menyimpan data untuk ip_id %d: %w", r.IPID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
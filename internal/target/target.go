@@ -0,0 +1,94 @@
+// Package target membaca daftar target monitoring dari tabel ip_monitor.
+package target
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Target adalah satu baris ip_monitor
+type Target struct {
+	ID          int
+	IP          string
+	StatusID    int
+	ReasonID    int
+	CheckType   string
+	CheckParams string
+}
+
+// Load mengambil semua target dari tabel ip_monitor. Baris yang gagal
+// dibaca dilewati supaya satu data rusak tidak menghentikan monitoring.
+func Load(db *sql.DB, table string) ([]Target, error) {
+	rows, err := db.Query("SELECT id, ip, status_id, reason_id, check_type, check_params FROM " + table)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data dari %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var targets []Target
+	for rows.Next() {
+		var t Target
+		var checkParams sql.NullString
+		if err := rows.Scan(&t.ID, &t.IP, &t.StatusID, &t.ReasonID, &t.CheckType, &checkParams); err != nil {
+			log.Printf("Gagal membaca baris %s: %v", table, err)
+			continue
+		}
+		t.CheckParams = checkParams.String
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("gagal membaca data %s: %w", table, err)
+	}
+
+	return targets, nil
+}
+
+// IDs mengembalikan id semua target di ip_monitor, urut
+func IDs(db *sql.DB, table string) ([]int, error) {
+	rows, err := db.Query("SELECT id FROM " + table + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca id dari %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("gagal membaca id dari %s: %w", table, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Groups mengembalikan group_name setiap target yang punya grup
+func Groups(db *sql.DB, table string) (map[int]string, error) {
+	return byID(db, table, "group_name", "grup")
+}
+
+// Calendars mengembalikan calendar_name setiap target yang punya kalender
+func Calendars(db *sql.DB, table string) (map[int]string, error) {
+	return byID(db, table, "calendar_name", "kalender")
+}
+
+// Nilai satu kolom teks ip_monitor per id, yang NULL / kosong dilewati
+func byID(db *sql.DB, table, column, label string) (map[int]string, error) {
+	rows, err := db.Query("SELECT id, " + column + " FROM " + table + " WHERE " + column + " IS NOT NULL AND " + column + " <> ''")
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s dari %s: %w", label, table, err)
+	}
+	defer rows.Close()
+
+	values := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, fmt.Errorf("gagal membaca %s dari %s: %w", label, table, err)
+		}
+		values[id] = value
+	}
+	return values, rows.Err()
+}
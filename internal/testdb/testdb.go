@@ -0,0 +1,64 @@
+// Package testdb menyiapkan database MySQL sementara untuk test yang butuh
+// fitur MySQL (ON DUPLICATE KEY UPDATE, SELECT ... FOR UPDATE). DSN server
+// diambil dari SLA_TEST_MYSQL_DSN; jika kosong test dilewati.
+package testdb
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL membuat database baru yang sudah dimigrasi dan menghapusnya lagi
+// setelah test selesai
+func MySQL(t testing.TB) (*sql.DB, *config.Config) {
+	t.Helper()
+	dsn := os.Getenv("SLA_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("SLA_TEST_MYSQL_DSN kosong, test MySQL dilewati")
+	}
+	parsed, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("SLA_TEST_MYSQL_DSN tidak valid: %v", err)
+	}
+
+	parsed.DBName = ""
+	server, err := sql.Open("mysql", parsed.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	name := fmt.Sprintf("sla_test_%d", time.Now().UnixNano())
+	if _, err := server.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatalf("gagal membuat database test: %v", err)
+	}
+
+	cfg := config.Default()
+	parsed.DBName = name
+	parsed.ParseTime = true
+	cfg.MySQL.DSN = parsed.FormatDSN()
+	db, err := store.OpenMySQL(cfg.MySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		if server, err := sql.Open("mysql", dsn); err == nil {
+			server.Exec("DROP DATABASE " + name)
+			server.Close()
+		}
+	})
+
+	if _, err := migrate.Up(db, migrate.MySQL, &cfg); err != nil {
+		t.Fatalf("gagal migrasi database test: %v", err)
+	}
+	return db, &cfg
+}
@@ -0,0 +1,165 @@
+// Package upload mengirim baris ping_results mentah dari SQLite lokal
+// (probe.sink: sqlite) ke MySQL secara bertahap.
+//
+// Posisi terakhir yang sudah terkirim (watermark, id SQLite) disimpan di
+// tabel upload_state di MySQL dan diperbarui di transaksi yang sama dengan
+// INSERT-nya, jadi setelah crash upload lanjut dari posisi yang benar tanpa
+// baris hilang atau dobel. Baris lokal hanya dihapus sampai watermark yang
+// sudah tercatat di MySQL.
+package upload
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+)
+
+// Kolom ping_results yang dikirim, id SQLite tidak ikut karena MySQL punya id sendiri
+var columns = []string{
+	"sample_id", "ip_id", "timestamp", "status", "response_time", "status_id", "reason_id",
+	"packet_loss", "jitter", "rtt_min", "rtt_max", "rtt_stddev", "retry_count", "ttl",
+}
+
+// Result adalah hasil satu kali Raw
+type Result struct {
+	Shipped   int   // baris yang dikirim ke MySQL
+	Pruned    int64 // baris lokal yang dihapus
+	Watermark int64 // id SQLite terakhir yang sudah tersimpan di MySQL
+	ResetFrom int64 // watermark lama yang di-reset ke 0 karena file lokal dibuat ulang, 0 jika tidak
+}
+
+// Raw mengirim semua baris SQLite setelah watermark sumber ini ke MySQL,
+// batchSize baris per transaksi, lalu (jika prune) menghapus baris lokal
+// yang sudah tersimpan. dialect adalah jenis database tujuan (MySQL di
+// produksi).
+//
+// Jika id terbesar di SQLite lokal lebih kecil dari watermark, file lokal
+// dibuat ulang dan id mulai lagi dari bawah; watermark di-reset ke 0 supaya
+// baris baru tidak terlewat (baris yang sudah ada dilewati lewat sample_id).
+func Raw(sqliteDB, destDB *sql.DB, dialect migrate.Dialect, tables config.TablesConfig, source string, batchSize int, prune bool) (Result, error) {
+	var result Result
+	var shipErr error
+	for {
+		n, watermark, resetFrom, err := shipBatch(sqliteDB, destDB, dialect, tables, source, batchSize)
+		if err != nil {
+			shipErr = err
+			break
+		}
+		result.Watermark = watermark
+		if resetFrom > 0 {
+			result.ResetFrom = resetFrom
+		}
+		result.Shipped += n
+		if n < batchSize {
+			break
+		}
+	}
+
+	// Hanya sampai watermark yang sudah di-commit, juga jika batch berikutnya
+	// gagal. Batch pertama gagal = watermark 0, tidak ada yang dihapus.
+	if prune && result.Watermark > 0 {
+		res, err := sqliteDB.Exec("DELETE FROM "+tables.PingResults+" WHERE id <= ?", result.Watermark)
+		if err != nil {
+			return result, errors.Join(shipErr, fmt.Errorf("gagal menghapus baris lokal yang sudah terkirim: %w", err))
+		}
+		result.Pruned, _ = res.RowsAffected()
+	}
+	return result, shipErr
+}
+
+// Satu transaksi di database tujuan: baca watermark (dikunci), kirim baris
+// berikutnya, lalu simpan watermark baru. Mengembalikan jumlah baris,
+// watermark terbaru dan watermark lama jika di-reset.
+func shipBatch(sqliteDB, destDB *sql.DB, dialect migrate.Dialect, tables config.TablesConfig, source string, batchSize int) (int, int64, int64, error) {
+	tx, err := destDB.Begin()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer tx.Rollback()
+
+	// Baris state dibuat dulu supaya FOR UPDATE selalu mengunci baris yang ada,
+	// dua upload dengan source yang sama tidak bisa jalan bersamaan. SQLite
+	// tidak punya FOR UPDATE, INSERT ini sudah mengunci seluruh database.
+	insertState, lock, ignore := " ON DUPLICATE KEY UPDATE source = source", " FOR UPDATE", " ON DUPLICATE KEY UPDATE sample_id = sample_id"
+	if dialect != migrate.MySQL {
+		insertState, lock, ignore = " ON CONFLICT (source) DO NOTHING", "", " ON CONFLICT (sample_id) DO NOTHING"
+	}
+	_, err = tx.Exec("INSERT INTO "+tables.UploadState+" (source, last_id, updated_at) VALUES (?, 0, ?)"+insertState, source, time.Now())
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("gagal menyiapkan %s: %w", tables.UploadState, err)
+	}
+	var watermark int64
+	err = tx.QueryRow("SELECT last_id FROM "+tables.UploadState+" WHERE source = ?"+lock, source).Scan(&watermark)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("gagal membaca watermark %s: %w", source, err)
+	}
+
+	var maxID sql.NullInt64
+	if err := sqliteDB.QueryRow("SELECT MAX(id) FROM " + tables.PingResults).Scan(&maxID); err != nil {
+		return 0, watermark, 0, fmt.Errorf("gagal membaca %s lokal: %w", tables.PingResults, err)
+	}
+	var resetFrom int64
+	if maxID.Valid && maxID.Int64 < watermark {
+		resetFrom, watermark = watermark, 0
+	}
+
+	lastID, rows, err := readAfter(sqliteDB, tables.PingResults, watermark, batchSize)
+	if err != nil {
+		return 0, watermark, 0, err
+	}
+	if len(rows) == 0 {
+		return 0, watermark, 0, tx.Commit()
+	}
+
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	values := strings.TrimSuffix(strings.Repeat(row+", ", len(rows)), ", ")
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for _, r := range rows {
+		args = append(args, r...)
+	}
+	// sample_id yang sudah ada (misalnya watermark pernah di-reset) diabaikan
+	_, err = tx.Exec("INSERT INTO "+tables.PingResults+" ("+strings.Join(columns, ", ")+") VALUES "+values+ignore, args...)
+	if err != nil {
+		return 0, watermark, 0, fmt.Errorf("gagal mengirim %d baris ke %s: %w", len(rows), tables.PingResults, err)
+	}
+
+	_, err = tx.Exec("UPDATE "+tables.UploadState+" SET last_id = ?, updated_at = ? WHERE source = ?", lastID, time.Now(), source)
+	if err != nil {
+		return 0, watermark, 0, fmt.Errorf("gagal menyimpan watermark %s: %w", source, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, watermark, 0, fmt.Errorf("gagal commit upload: %w", err)
+	}
+	return len(rows), lastID, resetFrom, nil
+}
+
+// Membaca maksimal limit baris SQLite dengan id > after, urut id.
+// Nilai kolom diteruskan apa adanya (NULL tetap NULL).
+func readAfter(db *sql.DB, table string, after int64, limit int) (int64, [][]interface{}, error) {
+	rows, err := db.Query("SELECT id, "+strings.Join(columns, ", ")+" FROM "+table+" WHERE id > ? ORDER BY id LIMIT ?", after, limit)
+	if err != nil {
+		return after, nil, fmt.Errorf("gagal membaca %s lokal: %w", table, err)
+	}
+	defer rows.Close()
+
+	lastID := after
+	var result [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns)+1)
+		dest[0] = &lastID
+		for i := range values {
+			dest[i+1] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return after, nil, fmt.Errorf("gagal membaca %s lokal: %w", table, err)
+		}
+		result = append(result, values)
+	}
+	return lastID, result, rows.Err()
+}
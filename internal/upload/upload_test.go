@@ -0,0 +1,199 @@
+package upload_test
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/store"
+	"sla_uptime/internal/upload"
+)
+
+// Di produksi tujuannya MySQL, upload_state hanya ada di migrasi MySQL
+const uploadStateTable = `CREATE TABLE upload_state (source TEXT NOT NULL PRIMARY KEY, last_id BIGINT NOT NULL DEFAULT 0, updated_at DATETIME NOT NULL)`
+
+func openSQLite(t *testing.T, name string, cfg *config.Config) *sql.DB {
+	t.Helper()
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := migrate.Up(db, migrate.SQLite, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+type fixture struct {
+	t           *testing.T
+	tables      config.TablesConfig
+	local, dest *sql.DB
+	next        int // nomor sample_id berikutnya
+}
+
+func newFixture(t *testing.T) *fixture {
+	cfg := config.Default()
+	f := &fixture{t: t, tables: cfg.Tables, local: openSQLite(t, "local.db", &cfg), dest: openSQLite(t, "dest.db", &cfg)}
+	f.exec(f.dest, uploadStateTable)
+	return f
+}
+
+func (f *fixture) exec(db *sql.DB, query string, args ...any) {
+	f.t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// Menambah baris lokal untuk setiap ip_id
+func (f *fixture) probe(ipIDs ...int) {
+	f.t.Helper()
+	for _, ipID := range ipIDs {
+		f.next++
+		f.exec(f.local, "INSERT INTO "+f.tables.PingResults+" (sample_id, ip_id, timestamp, status) VALUES (?, ?, ?, 1)",
+			fmt.Sprintf("sample-%d", f.next), ipID, time.Date(2024, 1, 31, 10, 0, f.next, 0, time.UTC))
+	}
+}
+
+func (f *fixture) run(batchSize int, prune bool) (upload.Result, error) {
+	return upload.Raw(f.local, f.dest, migrate.SQLite, f.tables, "probe-1", batchSize, prune)
+}
+
+func (f *fixture) ipIDs(db *sql.DB) string {
+	f.t.Helper()
+	rows, err := db.Query("SELECT ip_id FROM " + f.tables.PingResults + " ORDER BY id")
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			f.t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	return fmt.Sprint(ids)
+}
+
+func (f *fixture) watermark() int64 {
+	f.t.Helper()
+	var last int64
+	if err := f.dest.QueryRow("SELECT last_id FROM upload_state WHERE source = 'probe-1'").Scan(&last); err != nil && err != sql.ErrNoRows {
+		f.t.Fatal(err)
+	}
+	return last
+}
+
+// Run kedua hanya mengirim baris di atas watermark
+func TestRawIncremental(t *testing.T) {
+	f := newFixture(t)
+	f.probe(1, 2, 3)
+	result, err := f.run(2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Shipped != 3 || result.Watermark != 3 || f.watermark() != 3 {
+		t.Errorf("run pertama %+v, watermark tersimpan %d, seharusnya 3 baris dan watermark 3", result, f.watermark())
+	}
+
+	f.probe(4, 5)
+	if result, err = f.run(2, false); err != nil {
+		t.Fatal(err)
+	}
+	if result.Shipped != 2 || result.Watermark != 5 {
+		t.Errorf("run kedua %+v, seharusnya 2 baris dan watermark 5", result)
+	}
+	if got := f.ipIDs(f.dest); got != "[1 2 3 4 5]" {
+		t.Errorf("tujuan %s, seharusnya [1 2 3 4 5] tanpa dobel", got)
+	}
+	if got := f.ipIDs(f.local); got != "[1 2 3 4 5]" {
+		t.Errorf("lokal %s, seharusnya tetap utuh tanpa prune", got)
+	}
+}
+
+// ip_id 99 ditolak database tujuan
+func rejectIP99(f *fixture) {
+	f.exec(f.dest, "CREATE TRIGGER reject BEFORE INSERT ON "+f.tables.PingResults+
+		" WHEN NEW.ip_id = 99 BEGIN SELECT RAISE(ABORT, 'ditolak'); END")
+}
+
+// INSERT gagal: watermark dan baris lokal tidak berubah, juga dengan prune
+func TestRawFailure(t *testing.T) {
+	f := newFixture(t)
+	f.probe(1, 2)
+	if _, err := f.run(10, false); err != nil {
+		t.Fatal(err)
+	}
+	rejectIP99(f)
+
+	f.probe(99)
+	if _, err := f.run(10, true); err == nil {
+		t.Fatal("upload berhasil, seharusnya gagal")
+	}
+	if f.watermark() != 2 {
+		t.Errorf("watermark %d setelah gagal, seharusnya tetap 2", f.watermark())
+	}
+	if got := f.ipIDs(f.local); got != "[1 2 99]" {
+		t.Errorf("lokal %s, seharusnya tetap [1 2 99]", got)
+	}
+	if got := f.ipIDs(f.dest); got != "[1 2]" {
+		t.Errorf("tujuan %s, seharusnya tetap [1 2]", got)
+	}
+}
+
+// Batch pertama masuk, batch kedua gagal: prune hanya menghapus baris sampai
+// watermark yang sudah di-commit
+func TestRawPrunePartial(t *testing.T) {
+	f := newFixture(t)
+	rejectIP99(f)
+	f.probe(1, 2, 99, 3)
+	result, err := f.run(2, true)
+	if err == nil {
+		t.Fatal("upload berhasil, seharusnya gagal")
+	}
+	if result.Shipped != 2 || result.Watermark != 2 || result.Pruned != 2 || f.watermark() != 2 {
+		t.Errorf("%+v, watermark tersimpan %d, seharusnya 2 baris terkirim dan dihapus, watermark 2", result, f.watermark())
+	}
+	if got := f.ipIDs(f.local); got != "[99 3]" {
+		t.Errorf("lokal %s, seharusnya [99 3]", got)
+	}
+	if got := f.ipIDs(f.dest); got != "[1 2]" {
+		t.Errorf("tujuan %s, seharusnya [1 2]", got)
+	}
+}
+
+// File SQLite lokal dibuat ulang sehingga id mulai lagi dari 1: watermark
+// di-reset supaya baris baru tetap terkirim
+func TestRawRecreatedLocal(t *testing.T) {
+	f := newFixture(t)
+	f.probe(1, 2, 3, 4)
+	if _, err := f.run(10, true); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	f.local = openSQLite(t, "local-baru.db", &cfg)
+	f.probe(5, 6)
+	result, err := f.run(10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ResetFrom != 4 || result.Shipped != 2 || result.Watermark != 2 || f.watermark() != 2 {
+		t.Errorf("%+v, watermark tersimpan %d, seharusnya reset dari 4, 2 baris, watermark 2", result, f.watermark())
+	}
+	if got := f.ipIDs(f.dest); got != "[1 2 3 4 5 6]" {
+		t.Errorf("tujuan %s, seharusnya [1 2 3 4 5 6]", got)
+	}
+
+	// Setelah prune file lokal kosong, itu bukan file baru
+	if result, err = f.run(10, true); err != nil || result.ResetFrom != 0 || f.watermark() != 2 {
+		t.Errorf("run kosong %+v (%v), seharusnya tanpa reset", result, err)
+	}
+}
@@ -0,0 +1,46 @@
+package writer
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Error MySQL karena isi baris: duplikat, NULL, nilai di luar jangkauan,
+// foreign key dan check constraint. Error MySQL lain (tabel atau kolom hilang,
+// hak akses, disk penuh, server read-only, timeout) tidak berhubungan dengan
+// baris tertentu, jadi batch di-spool dan dicoba lagi.
+var permanentMySQL = map[uint16]bool{
+	1048: true, // ER_BAD_NULL_ERROR
+	1062: true, // ER_DUP_ENTRY
+	1264: true, // ER_WARN_DATA_OUT_OF_RANGE
+	1265: true, // WARN_DATA_TRUNCATED
+	1366: true, // ER_TRUNCATED_WRONG_VALUE_FOR_FIELD
+	1406: true, // ER_DATA_TOO_LONG
+	1452: true, // ER_NO_REFERENCED_ROW_2 (foreign key)
+	3819: true, // ER_CHECK_CONSTRAINT_VIOLATED
+}
+
+// Error SQLite karena isi baris, mengirim ulang tidak akan berhasil
+var permanentSQLite = map[sqlite3.ErrNo]bool{
+	sqlite3.ErrConstraint: true,
+	sqlite3.ErrMismatch:   true,
+	sqlite3.ErrTooBig:     true,
+	sqlite3.ErrRange:      true,
+}
+
+// permanent mengembalikan true jika err ditolak database karena isi batch
+// (data / constraint), jadi batch yang sama akan selalu gagal. Error lain
+// (koneksi putus, skema, hak akses, disk penuh) dianggap sementara dan dicoba lagi.
+func permanent(err error) bool {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return permanentMySQL[myErr.Number]
+	}
+	var liteErr sqlite3.Error
+	if errors.As(err, &liteErr) {
+		return permanentSQLite[liteErr.Code]
+	}
+	return false
+}
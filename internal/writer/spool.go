@@ -0,0 +1,176 @@
+package writer
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"sla_uptime/internal/store"
+)
+
+// Spool adalah antrian lokal di file SQLite (mode WAL) untuk hasil probe yang
+// belum bisa ditulis ke MySQL. Baris dibaca lagi sesuai urutan masuk (seq)
+// dan baru dihapus setelah berhasil ditulis ke MySQL. Baris yang ditolak
+// MySQL karena isinya dipindah ke tabel dead_letter di file yang sama.
+type Spool struct {
+	db *sql.DB
+}
+
+// OpenSpool membuka (atau membuat) file spool. Tabelnya milik writer sendiri,
+// jadi dibuat langsung di sini, tidak lewat migrasi.
+func OpenSpool(path string) (*Spool, error) {
+	db, err := store.OpenSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	// Satu koneksi saja supaya PRAGMA berlaku untuk semua query
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+		`CREATE TABLE IF NOT EXISTS spool (
+			seq INTEGER PRIMARY KEY AUTOINCREMENT,
+			sample_id TEXT NOT NULL,
+			ip_id INT,
+			timestamp DATETIME,
+			status TEXT,
+			response_time REAL,
+			status_id INT,
+			reason_id INT,
+			packet_loss REAL,
+			jitter REAL,
+			rtt_min REAL,
+			rtt_max REAL,
+			rtt_stddev REAL,
+			retry_count INT,
+			ttl INT
+		)`,
+		`CREATE TABLE IF NOT EXISTS dead_letter (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			sample_id TEXT NOT NULL,
+			ip_id INT,
+			timestamp DATETIME,
+			status TEXT,
+			response_time REAL,
+			status_id INT,
+			reason_id INT,
+			packet_loss REAL,
+			jitter REAL,
+			rtt_min REAL,
+			rtt_max REAL,
+			rtt_stddev REAL,
+			retry_count INT,
+			ttl INT,
+			error TEXT NOT NULL,
+			failed_at DATETIME NOT NULL
+		)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("gagal menyiapkan spool %s: %w", path, err)
+		}
+	}
+	// File spool dari versi sebelumnya belum punya kolom ttl
+	for _, table := range []string{"spool", "dead_letter"} {
+		var n int
+		err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = 'ttl'", table).Scan(&n)
+		if err == nil && n == 0 {
+			_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN ttl INT")
+		}
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("gagal menyiapkan spool %s: %w", path, err)
+		}
+	}
+	return &Spool{db: db}, nil
+}
+
+// Close menutup file spool
+func (s *Spool) Close() error {
+	return s.db.Close()
+}
+
+// Len mengembalikan jumlah baris yang masih menunggu dikirim
+func (s *Spool) Len() (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM spool").Scan(&n)
+	return n, err
+}
+
+// DeadLetters mengembalikan jumlah baris di dead_letter
+func (s *Spool) DeadLetters() (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM dead_letter").Scan(&n)
+	return n, err
+}
+
+// Append menambahkan sampel ke akhir spool dalam satu transaksi
+func (s *Spool) Append(batch []Sample) error {
+	return s.insert("spool", nil, batch, nil)
+}
+
+// DeadLetter menyimpan sampel yang ditolak database beserta alasannya, supaya
+// tidak menahan antrian tapi juga tidak hilang
+func (s *Spool) DeadLetter(batch []Sample, reason error) error {
+	return s.insert("dead_letter", []string{"error", "failed_at"}, batch, []interface{}{reason.Error(), time.Now().UTC()})
+}
+
+// INSERT setiap sampel ke table dalam satu transaksi, extra ditambahkan di
+// belakang kolom sampel
+func (s *Spool) insert(table string, extraColumns []string, batch []Sample, extra []interface{}) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	names := append(append([]string{}, columns...), extraColumns...)
+	stmt, err := tx.Prepare("INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ") + ")")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, sample := range batch {
+		if _, err := stmt.Exec(append(sample.args(), extra...)...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Peek membaca maksimal limit sampel tertua tanpa menghapusnya.
+// Nilai seq terakhir dikembalikan untuk dipakai di Remove.
+func (s *Spool) Peek(limit int) ([]Sample, int64, error) {
+	rows, err := s.db.Query("SELECT seq, "+strings.Join(columns, ", ")+" FROM spool ORDER BY seq LIMIT ?", limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var batch []Sample
+	var lastSeq int64
+	for rows.Next() {
+		var sample Sample
+		var ttl sql.NullInt64
+		r := &sample.Result
+		err := rows.Scan(&sample.seq, &sample.SampleID, &sample.IPID, &sample.Timestamp, &r.Status, &r.ResponseTime,
+			&sample.StatusID, &sample.ReasonID, &r.PacketLoss, &r.Jitter, &r.MinRTT, &r.MaxRTT, &r.StdDevRTT, &r.Retries, &ttl)
+		if err != nil {
+			return nil, 0, err
+		}
+		r.TTL = int(ttl.Int64)
+		batch = append(batch, sample)
+		lastSeq = sample.seq
+	}
+	return batch, lastSeq, rows.Err()
+}
+
+// Remove menghapus semua sampel sampai seq tertentu (yang sudah tersimpan di MySQL)
+func (s *Spool) Remove(upToSeq int64) error {
+	_, err := s.db.Exec("DELETE FROM spool WHERE seq <= ?", upToSeq)
+	return err
+}
@@ -0,0 +1,416 @@
+// Package writer mengumpulkan hasil probe di antrian lalu menyimpannya ke
+// ping_results dengan INSERT banyak baris sekaligus, supaya lama satu siklus
+// probe tidak lagi tergantung latensi database.
+//
+// Jika spool dipasang, batch yang gagal ditulis (misalnya MySQL mati) disimpan
+// di file SQLite lokal lalu dikirim ulang sesuai urutan setelah MySQL bisa
+// dihubungi lagi. Setiap sampel punya sample_id unik, jadi baris yang terkirim
+// dua kali (at-least-once) diabaikan oleh database.
+//
+// Batch yang ditolak database karena isinya (data / constraint) tidak
+// dikirim ulang: batch dibagi dua terus sampai baris yang bermasalah ketemu,
+// baris lain tetap ditulis dan baris yang ditolak dipindah ke dead letter di
+// file spool, supaya satu baris rusak tidak menahan seluruh antrian.
+package writer
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/probe"
+
+	"github.com/google/uuid"
+)
+
+// Sample adalah satu baris ping_results
+type Sample struct {
+	SampleID  string // diisi otomatis oleh Write jika kosong
+	IPID      int
+	Timestamp time.Time // waktu probe dimulai
+	StatusID  int
+	ReasonID  int
+	Result    probe.Result
+
+	seq int64 // posisi di spool, diisi Spool.Peek
+}
+
+// Kolom ping_results yang diisi writer, urutannya sama dengan args()
+var columns = []string{
+	"sample_id", "ip_id", "timestamp", "status", "response_time", "status_id", "reason_id",
+	"packet_loss", "jitter", "rtt_min", "rtt_max", "rtt_stddev", "retry_count", "ttl",
+}
+
+func (s Sample) args() []interface{} {
+	r := s.Result
+	return []interface{}{
+		s.SampleID, s.IPID, s.Timestamp, r.Status, r.ResponseTime, s.StatusID, s.ReasonID,
+		r.PacketLoss, r.Jitter, r.MinRTT, r.MaxRTT, r.StdDevRTT, r.Retries, nullTTL(r.TTL),
+	}
+}
+
+// TTL 0 berarti tidak ada balasan ICMP (atau bukan check icmp), disimpan NULL
+func nullTTL(v int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v), Valid: v > 0}
+}
+
+// Stats adalah angka untuk memantau backpressure writer
+type Stats struct {
+	Queued       int           // jumlah sampel yang sedang menunggu di antrian
+	QueueSize    int           // kapasitas antrian
+	Written      int64         // total baris yang berhasil ditulis
+	Failed       int64         // total baris yang gagal ditulis dan tidak masuk spool (hilang)
+	Flushes      int64         // total INSERT yang dijalankan
+	Blocked      int64         // berapa kali Write harus menunggu karena antrian penuh
+	BlockedTime  time.Duration // total waktu menunggu karena antrian penuh
+	LastFlush    time.Duration // lama INSERT terakhir
+	LastFlushLen int           // jumlah baris di INSERT terakhir
+	Spooled      int64         // total baris yang masuk spool
+	Replayed     int64         // total baris dari spool yang sudah terkirim
+	SpoolPending int64         // baris di spool yang belum terkirim
+	DeadLettered int64         // total baris yang ditolak database karena isinya, dipindah ke dead letter
+}
+
+// Writer menyimpan sampel ke database secara batch di goroutine sendiri
+type Writer struct {
+	db      *sql.DB
+	dialect migrate.Dialect
+	table   string
+	cfg     config.WriterConfig
+
+	spool         *Spool
+	retryInterval time.Duration
+	retryAt       time.Time
+
+	queue chan Sample
+	done  chan struct{}
+	once  sync.Once
+
+	written, failed, flushes, blocked, blockedNanos atomic.Int64
+	lastFlushNanos, lastFlushLen                    atomic.Int64
+	spooled, replayed, spoolPending, deadLettered   atomic.Int64
+}
+
+// New membuat writer dan langsung menjalankan goroutine flush-nya.
+// Panggil Close supaya sisa antrian ikut tersimpan.
+func New(db *sql.DB, dialect migrate.Dialect, table string, cfg config.WriterConfig) *Writer {
+	w := &Writer{
+		db:      db,
+		dialect: dialect,
+		table:   table,
+		cfg:     cfg,
+		queue:   make(chan Sample, cfg.QueueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// NewWithSpool sama dengan New, tapi batch yang gagal ditulis disimpan di spool
+// dan dikirim ulang setiap retryInterval. Isi spool dari run sebelumnya
+// langsung ikut dikirim.
+func NewWithSpool(db *sql.DB, dialect migrate.Dialect, table string, cfg config.WriterConfig, spool *Spool, retryInterval time.Duration) (*Writer, error) {
+	pending, err := spool.Len()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca spool: %w", err)
+	}
+	if pending > 0 {
+		log.Printf("Spool berisi %d hasil ping dari run sebelumnya, akan dikirim ke %s", pending, table)
+	}
+	if dead, err := spool.DeadLetters(); err == nil && dead > 0 {
+		log.Printf("Dead letter spool berisi %d hasil ping yang ditolak %s, periksa tabel dead_letter", dead, table)
+	}
+
+	w := &Writer{
+		db:            db,
+		dialect:       dialect,
+		table:         table,
+		cfg:           cfg,
+		spool:         spool,
+		retryInterval: retryInterval,
+		queue:         make(chan Sample, cfg.QueueSize),
+		done:          make(chan struct{}),
+	}
+	w.spoolPending.Store(int64(pending))
+	go w.run()
+	return w, nil
+}
+
+// Write memasukkan sampel ke antrian. Jika antrian penuh, Write menunggu
+// (backpressure) dan kejadian ini dicatat di Stats.
+func (w *Writer) Write(s Sample) {
+	if s.SampleID == "" {
+		s.SampleID = uuid.NewString()
+	}
+
+	select {
+	case w.queue <- s:
+		return
+	default:
+	}
+
+	start := time.Now()
+	w.queue <- s
+	w.blocked.Add(1)
+	w.blockedNanos.Add(int64(time.Since(start)))
+}
+
+// Close menutup antrian dan menunggu semua sampel tersimpan (di database atau spool)
+func (w *Writer) Close() {
+	w.once.Do(func() {
+		close(w.queue)
+		<-w.done
+	})
+}
+
+// Stats mengembalikan angka terbaru writer
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Queued:       len(w.queue),
+		QueueSize:    cap(w.queue),
+		Written:      w.written.Load(),
+		Failed:       w.failed.Load(),
+		Flushes:      w.flushes.Load(),
+		Blocked:      w.blocked.Load(),
+		BlockedTime:  time.Duration(w.blockedNanos.Load()),
+		LastFlush:    time.Duration(w.lastFlushNanos.Load()),
+		LastFlushLen: int(w.lastFlushLen.Load()),
+		Spooled:      w.spooled.Load(),
+		Replayed:     w.replayed.Load(),
+		SpoolPending: w.spoolPending.Load(),
+		DeadLettered: w.deadLettered.Load(),
+	}
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("antrian %d/%d, ditulis %d baris dalam %d flush, gagal %d, antrian penuh %d kali (%v), flush terakhir %d baris %v, spool %d (masuk %d, terkirim ulang %d), dead letter %d",
+		s.Queued, s.QueueSize, s.Written, s.Flushes, s.Failed, s.Blocked, s.BlockedTime.Round(time.Millisecond),
+		s.LastFlushLen, s.LastFlush.Round(time.Millisecond), s.SpoolPending, s.Spooled, s.Replayed, s.DeadLettered)
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	flushTicker := time.NewTicker(w.cfg.FlushInterval)
+	defer flushTicker.Stop()
+
+	// Stats hanya di-log jika stats_interval diisi
+	var statsC <-chan time.Time
+	if w.cfg.StatsInterval > 0 {
+		statsTicker := time.NewTicker(w.cfg.StatsInterval)
+		defer statsTicker.Stop()
+		statsC = statsTicker.C
+	}
+
+	batch := make([]Sample, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case s, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, s)
+			if len(batch) >= w.cfg.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-flushTicker.C:
+			w.flush(batch)
+			batch = batch[:0]
+			w.replay()
+		case <-statsC:
+			log.Printf("Writer %s: %s", w.table, w.Stats())
+		}
+	}
+}
+
+func (w *Writer) flush(batch []Sample) {
+	if len(batch) == 0 {
+		return
+	}
+
+	// Selama spool masih berisi, batch baru ikut antri di belakangnya supaya
+	// urutan ke database tetap sama dengan urutan probe
+	if w.spoolPending.Load() > 0 {
+		w.toSpool(batch)
+		return
+	}
+
+	err := w.insertTimed(batch)
+	if err == nil {
+		w.written.Add(int64(len(batch)))
+		return
+	}
+	if permanent(err) {
+		log.Printf("%d hasil ping ditolak %s, mencari baris yang bermasalah: %v", len(batch), w.table, err)
+		written, handled, isolateErr := w.isolate(batch, err)
+		w.written.Add(int64(written))
+		if isolateErr == nil {
+			return
+		}
+		// Sisa batch yang belum dicoba diperlakukan seperti gagal biasa
+		batch, err = batch[handled:], isolateErr
+	}
+
+	if w.spool == nil {
+		w.failed.Add(int64(len(batch)))
+		log.Printf("Gagal menyimpan %d hasil ping ke %s: %v", len(batch), w.table, err)
+		return
+	}
+	log.Printf("Gagal menyimpan %d hasil ping ke %s, disimpan di spool lokal: %v", len(batch), w.table, err)
+	w.retryAt = time.Now().Add(w.retryInterval)
+	w.toSpool(batch)
+}
+
+func (w *Writer) toSpool(batch []Sample) {
+	if err := w.spool.Append(batch); err != nil {
+		w.failed.Add(int64(len(batch)))
+		log.Printf("Gagal menyimpan %d hasil ping ke spool, data hilang: %v", len(batch), err)
+		return
+	}
+	w.spooled.Add(int64(len(batch)))
+	w.spoolPending.Add(int64(len(batch)))
+}
+
+// Mengirim isi spool ke database dari yang paling lama. Dibatasi satu
+// flush_interval per panggilan supaya antrian tetap diproses.
+func (w *Writer) replay() {
+	if w.spool == nil || w.spoolPending.Load() == 0 || time.Now().Before(w.retryAt) {
+		return
+	}
+
+	deadline := time.Now().Add(w.cfg.FlushInterval)
+	for w.spoolPending.Load() > 0 && time.Now().Before(deadline) {
+		batch, lastSeq, err := w.spool.Peek(w.cfg.BatchSize)
+		if err != nil {
+			log.Printf("Gagal membaca spool: %v", err)
+			w.retryAt = time.Now().Add(w.retryInterval)
+			return
+		}
+		if len(batch) == 0 {
+			w.spoolPending.Store(0)
+			return
+		}
+
+		written, handled := len(batch), len(batch)
+		err = w.insertTimed(batch)
+		if err != nil && permanent(err) {
+			log.Printf("Kirim ulang %d hasil ping dari spool ditolak %s, mencari baris yang bermasalah: %v", len(batch), w.table, err)
+			written, handled, err = w.isolate(batch, err)
+		}
+		// Baris yang sudah ditulis atau dipindah ke dead letter dihapus dari
+		// spool, walaupun sisanya gagal. Jika proses mati sebelum baris ini
+		// dihapus, batch yang sama dikirim lagi di run berikutnya dan
+		// diabaikan karena sample_id sudah ada.
+		if handled > 0 {
+			removeSeq := lastSeq
+			if handled < len(batch) {
+				removeSeq = batch[handled-1].seq
+			}
+			if err := w.spool.Remove(removeSeq); err != nil {
+				log.Printf("Gagal menghapus spool yang sudah terkirim: %v", err)
+				w.retryAt = time.Now().Add(w.retryInterval)
+				return
+			}
+			w.replayed.Add(int64(written))
+			w.spoolPending.Add(-int64(handled))
+		}
+		if err != nil {
+			log.Printf("Kirim ulang spool ke %s gagal, dicoba lagi dalam %v: %v", w.table, w.retryInterval, err)
+			w.retryAt = time.Now().Add(w.retryInterval)
+			return
+		}
+	}
+
+	if w.spoolPending.Load() == 0 {
+		log.Printf("Spool kosong, semua hasil ping sudah terkirim ke %s", w.table)
+	}
+}
+
+// Menulis batch yang ditolak karena isinya dengan membaginya dua terus sampai
+// baris yang bermasalah ketemu. Baris yang diterima ditulis, baris yang
+// ditolak dipindah ke dead letter. Mengembalikan jumlah baris yang ditulis
+// dan jumlah baris awal batch yang sudah selesai (ditulis atau ditolak); jika
+// di tengah jalan muncul error sementara, sisa batch[handled:] belum dicoba.
+func (w *Writer) isolate(batch []Sample, cause error) (written, handled int, err error) {
+	if len(batch) == 1 {
+		w.reject(batch, cause)
+		return 0, 1, nil
+	}
+
+	half := len(batch) / 2
+	for _, part := range [][]Sample{batch[:half], batch[half:]} {
+		err := w.insertTimed(part)
+		if err == nil {
+			written += len(part)
+			handled += len(part)
+			continue
+		}
+		if !permanent(err) {
+			return written, handled, err
+		}
+		n, h, err := w.isolate(part, err)
+		written += n
+		handled += h
+		if err != nil {
+			return written, handled, err
+		}
+	}
+	return written, handled, nil
+}
+
+// Memindahkan sampel yang ditolak database ke dead letter spool. Tanpa spool
+// sampel itu hanya dicatat sebagai gagal.
+func (w *Writer) reject(batch []Sample, cause error) {
+	for _, s := range batch {
+		log.Printf("Hasil ping %s (ip_id %d, %s) ditolak %s: %v", s.SampleID, s.IPID, s.Timestamp.Format(time.RFC3339), w.table, cause)
+	}
+	if w.spool == nil {
+		w.failed.Add(int64(len(batch)))
+		return
+	}
+	if err := w.spool.DeadLetter(batch, cause); err != nil {
+		w.failed.Add(int64(len(batch)))
+		log.Printf("Gagal menyimpan %d hasil ping ke dead letter, data hilang: %v", len(batch), err)
+		return
+	}
+	w.deadLettered.Add(int64(len(batch)))
+}
+
+func (w *Writer) insertTimed(batch []Sample) error {
+	start := time.Now()
+	err := w.insert(batch)
+	w.flushes.Add(1)
+	w.lastFlushNanos.Store(int64(time.Since(start)))
+	w.lastFlushLen.Store(int64(len(batch)))
+	return err
+}
+
+// INSERT banyak baris dalam satu statement, baris dengan sample_id yang
+// sudah ada diabaikan
+func (w *Writer) insert(batch []Sample) error {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	values := strings.TrimSuffix(strings.Repeat(row+", ", len(batch)), ", ")
+
+	args := make([]interface{}, 0, len(batch)*len(columns))
+	for _, s := range batch {
+		args = append(args, s.args()...)
+	}
+
+	query := "INSERT INTO " + w.table + " (" + strings.Join(columns, ", ") + ") VALUES " + values
+	if w.dialect == migrate.MySQL {
+		query += " ON DUPLICATE KEY UPDATE sample_id = sample_id"
+	} else {
+		query += " ON CONFLICT (sample_id) DO NOTHING"
+	}
+
+	_, err := w.db.Exec(query, args...)
+	return err
+}
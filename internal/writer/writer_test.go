@@ -0,0 +1,323 @@
+package writer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sla_uptime/internal/config"
+	"sla_uptime/internal/migrate"
+	"sla_uptime/internal/probe"
+	"sla_uptime/internal/store"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Tabel tujuan di SQLite. ip_id negatif ditolak CHECK constraint, dipakai
+// sebagai baris rusak yang selalu gagal.
+const targetTable = `CREATE TABLE ping_results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	sample_id TEXT UNIQUE,
+	ip_id INT CHECK (ip_id > 0),
+	timestamp DATETIME,
+	status TEXT,
+	response_time REAL,
+	status_id INT,
+	reason_id INT,
+	packet_loss REAL,
+	jitter REAL,
+	rtt_min REAL,
+	rtt_max REAL,
+	rtt_stddev REAL,
+	retry_count INT,
+	ttl INT
+)`
+
+func openTarget(t *testing.T, create bool) *sql.DB {
+	t.Helper()
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "target.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if create {
+		if _, err := db.Exec(targetTable); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func openTestSpool(t *testing.T) *Spool {
+	t.Helper()
+	spool, err := OpenSpool(filepath.Join(t.TempDir(), "spool.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { spool.Close() })
+	return spool
+}
+
+func samples(ipIDs ...int) []Sample {
+	at := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	batch := make([]Sample, len(ipIDs))
+	for i, id := range ipIDs {
+		batch[i] = Sample{
+			SampleID:  fmt.Sprintf("sample-%d", i),
+			IPID:      id,
+			Timestamp: at.Add(time.Duration(i) * time.Second),
+			Result:    probe.Result{Status: "1", ResponseTime: 1},
+		}
+	}
+	return batch
+}
+
+func testConfig() config.WriterConfig {
+	return config.WriterConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond, QueueSize: 100}
+}
+
+// Menunggu sampai cond terpenuhi, paling lama 5 detik
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("menunggu %s terlalu lama", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func countRows(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestPermanent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"data terlalu panjang", &mysql.MySQLError{Number: 1406}, true},
+		{"foreign key", fmt.Errorf("insert: %w", &mysql.MySQLError{Number: 1452}), true},
+		{"deadlock", &mysql.MySQLError{Number: 1213}, false},
+		{"lock wait timeout", &mysql.MySQLError{Number: 1205}, false},
+		{"too many connections", &mysql.MySQLError{Number: 1040}, false},
+		{"tabel tidak ada", &mysql.MySQLError{Number: 1146}, false},
+		{"tabel penuh", &mysql.MySQLError{Number: 1114}, false},
+		{"hak akses ditolak", &mysql.MySQLError{Number: 1142}, false},
+		{"duplikat", &mysql.MySQLError{Number: 1062}, true},
+		{"koneksi putus", mysql.ErrInvalidConn, false},
+		{"koneksi ditolak", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, false},
+		{"sqlite constraint", sqlite3.Error{Code: sqlite3.ErrConstraint}, true},
+		{"sqlite busy", sqlite3.Error{Code: sqlite3.ErrBusy}, false},
+		{"database ditutup", sql.ErrConnDone, false},
+	}
+	for _, tt := range tests {
+		if got := permanent(tt.err); got != tt.want {
+			t.Errorf("%s: permanent = %v, seharusnya %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TTL ikut tersimpan di spool, 0 (tanpa balasan) disimpan NULL. File spool
+// lama tanpa kolom ttl dilengkapi saat dibuka.
+func TestSpoolTTL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool.db")
+	old, err := store.OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.Exec(`CREATE TABLE spool (seq INTEGER PRIMARY KEY AUTOINCREMENT, sample_id TEXT NOT NULL, ip_id INT,
+		timestamp DATETIME, status TEXT, response_time REAL, status_id INT, reason_id INT, packet_loss REAL,
+		jitter REAL, rtt_min REAL, rtt_max REAL, rtt_stddev REAL, retry_count INT)`)
+	old.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spool, err := OpenSpool(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+	batch := samples(1, 2)
+	batch[0].Result.TTL = 64
+	if err := spool.Append(batch); err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := spool.Peek(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Result.TTL != 64 || got[1].Result.TTL != 0 {
+		t.Fatalf("spool %+v, seharusnya TTL 64 dan 0", got)
+	}
+	var nulls int
+	if err := spool.db.QueryRow("SELECT COUNT(*) FROM spool WHERE ttl IS NULL").Scan(&nulls); err != nil || nulls != 1 {
+		t.Errorf("%d baris dengan ttl NULL (%v), seharusnya 1", nulls, err)
+	}
+}
+
+func TestSpoolOrder(t *testing.T) {
+	spool := openTestSpool(t)
+	if err := spool.Append(samples(1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.Append(samples(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	batch, lastSeq, err := spool.Peek(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != 2 || batch[0].IPID != 1 || batch[1].IPID != 2 {
+		t.Fatalf("Peek(2) = %+v, seharusnya ip_id 1 dan 2", batch)
+	}
+	if err := spool.Remove(lastSeq); err != nil {
+		t.Fatal(err)
+	}
+
+	batch, _, err = spool.Peek(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != 1 || batch[0].IPID != 3 {
+		t.Fatalf("setelah Remove: %+v, seharusnya hanya ip_id 3", batch)
+	}
+	if !batch[0].Timestamp.Equal(samples(3)[0].Timestamp) {
+		t.Errorf("timestamp berubah di spool: %s", batch[0].Timestamp)
+	}
+}
+
+// Selama tabel tujuan tidak ada (error sementara), batch masuk spool. Setelah
+// tabel ada, isi spool dikirim ulang sesuai urutan dan spool kosong.
+func TestWriterSpoolAndReplay(t *testing.T) {
+	db := openTarget(t, false)
+	spool := openTestSpool(t)
+	w, err := NewWithSpool(db, migrate.SQLite, "ping_results", testConfig(), spool, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, s := range samples(1, 2, 3) {
+		w.Write(s)
+	}
+	waitFor(t, "batch masuk spool", func() bool { return w.Stats().SpoolPending == 3 })
+
+	if _, err := db.Exec(targetTable); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "spool terkirim", func() bool { return w.Stats().SpoolPending == 0 })
+
+	rows, err := db.Query("SELECT ip_id FROM ping_results ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, id)
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("urutan di database %v, seharusnya [1 2 3]", got)
+	}
+	if s := w.Stats(); s.Replayed != 3 || s.Failed != 0 {
+		t.Errorf("stats %s", s)
+	}
+}
+
+// Satu baris rusak tidak boleh menahan baris lain di batch yang sama, dan
+// tidak boleh membuat batch berikutnya masuk spool
+func TestWriterPoisonBatch(t *testing.T) {
+	db := openTarget(t, true)
+	spool := openTestSpool(t)
+	w, err := NewWithSpool(db, migrate.SQLite, "ping_results", testConfig(), spool, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range samples(1, 2, -1, 3, 4) {
+		w.Write(s)
+	}
+	waitFor(t, "batch ditulis", func() bool { return w.Stats().DeadLettered == 1 })
+
+	next := samples(5)
+	next[0].SampleID = "sample-next"
+	w.Write(next[0])
+	w.Close()
+
+	if n := countRows(t, db, "ping_results"); n != 5 {
+		t.Errorf("%d baris di database, seharusnya 5", n)
+	}
+	s := w.Stats()
+	if s.Written != 5 || s.DeadLettered != 1 || s.Spooled != 0 || s.SpoolPending != 0 {
+		t.Errorf("stats %s", s)
+	}
+	if dead, err := spool.DeadLetters(); err != nil || dead != 1 {
+		t.Errorf("dead letter %d (%v), seharusnya 1", dead, err)
+	}
+}
+
+// Baris rusak yang sudah terlanjur masuk spool dipindah ke dead letter saat
+// replay, baris lain tetap terkirim dan spool tidak macet
+func TestReplayPoisonBatch(t *testing.T) {
+	db := openTarget(t, true)
+	spool := openTestSpool(t)
+	if err := spool.Append(samples(1, -1, 2, -2, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWithSpool(db, migrate.SQLite, "ping_results", testConfig(), spool, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "spool terkirim", func() bool { return w.Stats().SpoolPending == 0 })
+	w.Close()
+
+	if n := countRows(t, db, "ping_results"); n != 3 {
+		t.Errorf("%d baris di database, seharusnya 3", n)
+	}
+	if n, err := spool.Len(); err != nil || n != 0 {
+		t.Errorf("spool berisi %d (%v), seharusnya kosong", n, err)
+	}
+	if dead, err := spool.DeadLetters(); err != nil || dead != 2 {
+		t.Errorf("dead letter %d (%v), seharusnya 2", dead, err)
+	}
+	if s := w.Stats(); s.Replayed != 3 || s.DeadLettered != 2 {
+		t.Errorf("stats %s", s)
+	}
+}
+
+// Tanpa spool baris yang ditolak dihitung gagal, baris lain tetap ditulis
+func TestWriterPoisonWithoutSpool(t *testing.T) {
+	db := openTarget(t, true)
+	w := New(db, migrate.SQLite, "ping_results", testConfig())
+	for _, s := range samples(1, -1, 2) {
+		w.Write(s)
+	}
+	w.Close()
+
+	if n := countRows(t, db, "ping_results"); n != 2 {
+		t.Errorf("%d baris di database, seharusnya 2", n)
+	}
+	if s := w.Stats(); s.Written != 2 || s.Failed != 1 {
+		t.Errorf("stats %s", s)
+	}
+}